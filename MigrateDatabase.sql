@@ -0,0 +1,82 @@
+-- Schema changes, in the order in which they were made. Every statement can safely be run more than once.
+
+-- The Snooze button on the alert list silences an alert until this time
+ALTER TABLE slackstockbot.alertsubscription ADD COLUMN IF NOT EXISTS snoozeduntil TIMESTAMP WITH TIME ZONE NULL;
+
+-- Alerts that are created in the alert modal can have an expiry date
+ALTER TABLE slackstockbot.alertsubscription ADD COLUMN IF NOT EXISTS expiresat TIMESTAMP WITH TIME ZONE NULL;
+
+-- The workspaces that installed the bot through OAuth, with the bot token that Slack issued to each of them
+CREATE TABLE IF NOT EXISTS slackstockbot.workspace (
+	teamid VARCHAR(20) PRIMARY KEY,
+	teamname VARCHAR(255) NOT NULL DEFAULT '',
+	botuserid VARCHAR(20) NOT NULL DEFAULT '',
+	bottoken VARCHAR(255) NOT NULL,
+	scope TEXT NOT NULL DEFAULT '',
+	installedby VARCHAR(20) NOT NULL DEFAULT '',
+	installedat TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- Alerts belong to the workspace that they were created in. Alerts from before OAuth have an empty team,
+-- and are moved into the team of their user the next time that the user touches their alerts.
+ALTER TABLE slackstockbot.alertsubscription ADD COLUMN IF NOT EXISTS teamid VARCHAR(20) NOT NULL DEFAULT '';
+
+-- The settings that each user picks with /quote-settings
+CREATE TABLE IF NOT EXISTS slackstockbot.userpreference (
+	teamid VARCHAR(20) NOT NULL DEFAULT '',
+	slackuser VARCHAR(20) NOT NULL,
+	decimals INTEGER NOT NULL DEFAULT 2,
+	currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+	timezone VARCHAR(64) NOT NULL DEFAULT '',
+	defaultchannel VARCHAR(80) NOT NULL DEFAULT '',
+	delivery VARCHAR(10) NOT NULL DEFAULT 'channel',
+	PRIMARY KEY (teamid, slackuser)
+);
+
+-- The currency of the target price of an alert. Empty means the currency that the symbol is listed in.
+ALTER TABLE slackstockbot.alertsubscription ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT '';
+
+-- FX pairs and crypto coins are priced with more than two decimals
+ALTER TABLE slackstockbot.stockprice ALTER COLUMN price TYPE DOUBLE PRECISION;
+ALTER TABLE slackstockbot.alertsubscription ALTER COLUMN targetprice TYPE DOUBLE PRECISION;
+
+-- The company name, exchange and currency of the symbols that have been quoted
+CREATE TABLE IF NOT EXISTS slackstockbot.symbol (
+	symbol VARCHAR(20) PRIMARY KEY,
+	name VARCHAR(255) NOT NULL DEFAULT '',
+	exchange VARCHAR(80) NOT NULL DEFAULT '',
+	currency VARCHAR(3) NOT NULL DEFAULT '',
+	assettype VARCHAR(20) NOT NULL DEFAULT '',
+	updatedat TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- Users who turn on events=on in /quote-settings get a DM the day before the earnings, dividends and splits of their alerted symbols
+ALTER TABLE slackstockbot.userpreference ADD COLUMN IF NOT EXISTS eventreminders BOOLEAN NOT NULL DEFAULT false;
+
+-- When the target price of an alert was last set, so that a split only moves the targets that were set before it
+ALTER TABLE slackstockbot.alertsubscription ADD COLUMN IF NOT EXISTS targetsetat TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now();
+
+-- The splits whose alerts have been adjusted, so that no split is applied twice
+CREATE TABLE IF NOT EXISTS slackstockbot.splitadjustment (
+	symbol VARCHAR(20) NOT NULL,
+	splitdate DATE NOT NULL,
+	ratio DOUBLE PRECISION NOT NULL,
+	appliedat TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+	PRIMARY KEY (symbol, splitdate)
+);
+
+-- Alerts on the news of a symbol: either on articles that mention a keyword, or on the number of articles in a day
+CREATE TABLE IF NOT EXISTS slackstockbot.newsalert (
+	id SERIAL PRIMARY KEY,
+	teamid VARCHAR(20) NOT NULL DEFAULT '',
+	slackuser VARCHAR(20) NOT NULL,
+	channel VARCHAR(80) NOT NULL DEFAULT '',
+	symbol VARCHAR(20) NOT NULL,
+	keyword VARCHAR(100) NOT NULL DEFAULT '',
+	minarticles INTEGER NOT NULL DEFAULT 0,
+	lastcheckedat TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+	lastnotifiedat TIMESTAMP WITH TIME ZONE NULL
+);
+
+-- Prices are only converted when the user picks a currency. An empty currency means the currency that the symbol is listed in.
+ALTER TABLE slackstockbot.userpreference ALTER COLUMN currency SET DEFAULT '';
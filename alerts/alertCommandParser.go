@@ -0,0 +1,316 @@
+package alerts
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/magmasystems/SlackStockSlashCommand/stockbot"
+)
+
+// The grammar of the /quote-alert command:
+//
+//   command   = "" | "new" | "deleteall" | symbol "delete" | symbol alert
+//   alert     = [price] { direction | channel | option }
+//   direction = "above" | "below"
+//   channel   = "#name" | '"#name"'
+//   option    = key "=" value, where key is one of price, direction, channel, expires or currency
+//
+// Words are case-insensitive, and any value can be put in double or single quotes.
+
+// alertCommandKind - what a /quote-alert command asks for
+type alertCommandKind int
+
+const (
+	commandList alertCommandKind = iota
+	commandNew
+	commandCreate
+	commandDelete
+	commandDeleteAll
+)
+
+// alertCommand - a parsed /quote-alert command. The params are nil for the commands that don't need them.
+type alertCommand struct {
+	kind   alertCommandKind
+	params *createAlertParams
+}
+
+// alertToken - a word in the command. Keyword arguments are split into the key and the value.
+type alertToken struct {
+	key    string
+	text   string
+	quoted bool
+}
+
+func (token alertToken) String() string {
+	if token.key != "" {
+		return token.key + "=" + token.text
+	}
+	return token.text
+}
+
+// The quotes that Slack clients put in when "smart quotes" are turned on
+var closingQuotes = map[rune]rune{'"': '"', '\'': '\'', '“': '”', '‘': '’'}
+
+// tokenizeAlertCommand - splits the command text into words, keeping quoted strings together
+func tokenizeAlertCommand(text string) ([]alertToken, error) {
+	var tokens []alertToken
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); {
+		if runes[i] == ' ' || runes[i] == '\t' || runes[i] == '\n' {
+			i++
+			continue
+		}
+
+		token := alertToken{}
+		var word strings.Builder
+
+		for i < len(runes) && runes[i] != ' ' && runes[i] != '\t' && runes[i] != '\n' {
+			if closing, ok := closingQuotes[runes[i]]; ok && word.Len() == 0 {
+				end := i + 1
+				for end < len(runes) && runes[end] != closing {
+					end++
+				}
+				if end == len(runes) {
+					return nil, fmt.Errorf("the quote in %s is never closed", string(runes[i:]))
+				}
+				word.WriteString(string(runes[i+1 : end]))
+				token.quoted = true
+				i = end + 1
+				continue
+			}
+
+			if runes[i] == '=' && token.key == "" && !token.quoted {
+				token.key = strings.ToLower(word.String())
+				if token.key == "" {
+					return nil, fmt.Errorf("expected a name before the = in %s", string(runes[i:]))
+				}
+				word.Reset()
+				i++
+				continue
+			}
+
+			word.WriteRune(runes[i])
+			i++
+		}
+
+		token.text = word.String()
+		if token.key != "" && token.text == "" {
+			return nil, fmt.Errorf("expected a value after %s=", token.key)
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+// parseAlertCommand - parses the text of a /quote-alert command, and explains exactly what is wrong if it cannot
+func parseAlertCommand(text string, now time.Time) (*alertCommand, error) {
+	tokens, err := tokenizeAlertCommand(text)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return &alertCommand{kind: commandList}, nil
+	}
+
+	first := tokens[0]
+	if first.key != "" {
+		return nil, fmt.Errorf("expected a symbol before %s", first)
+	}
+
+	if !first.quoted {
+		switch strings.ToLower(first.text) {
+		case "new":
+			return &alertCommand{kind: commandNew}, expectNothingAfter(tokens, "new")
+		case "deleteall":
+			return &alertCommand{kind: commandDeleteAll}, expectNothingAfter(tokens, "deleteall")
+		}
+	}
+
+	symbol := strings.ToUpper(first.text)
+	if !validSymbol.MatchString(symbol) {
+		return nil, fmt.Errorf("%s is not a valid symbol", first.text)
+	}
+	symbol = stockbot.NormalizeSymbol(symbol).Symbol // EUR/USD is stored as EURUSD, and BTCUSD as BTC-USD
+	params := &createAlertParams{symbol: symbol, direction: "ABOVE"}
+
+	if len(tokens) > 1 && tokens[1].key == "" && !tokens[1].quoted && strings.ToLower(tokens[1].text) == "delete" {
+		return &alertCommand{kind: commandDelete, params: params}, expectNothingAfter(tokens[1:], tokens[1].text)
+	}
+
+	parser := alertArgsParser{params: params, now: now}
+	if err = parser.parse(tokens); err != nil {
+		return nil, err
+	}
+
+	return &alertCommand{kind: commandCreate, params: params}, nil
+}
+
+func expectNothingAfter(tokens []alertToken, word string) error {
+	if len(tokens) > 1 {
+		return fmt.Errorf("unexpected %s after %s", tokens[1], word)
+	}
+	return nil
+}
+
+// alertArgsParser - parses everything after the symbol of a new alert
+type alertArgsParser struct {
+	params       *createAlertParams
+	now          time.Time
+	hasPrice     bool
+	hasDirection bool
+	hasChannel   bool
+	hasExpiry    bool
+	hasCurrency  bool
+}
+
+func (parser *alertArgsParser) parse(tokens []alertToken) error {
+	symbol := tokens[0].text
+	previous := symbol
+
+	for i, token := range tokens[1:] {
+		var err error
+
+		switch {
+		case token.key != "":
+			err = parser.parseOption(token)
+		case i == 0 && !token.quoted && !isDirection(token.text) && !strings.HasPrefix(token.text, "#"):
+			// The word right after the symbol is the price
+			err = parser.setPrice(token.text)
+		case !token.quoted && isDirection(token.text):
+			err = parser.setDirection(token.text)
+		case strings.HasPrefix(token.text, "#") || token.quoted:
+			err = parser.setChannel(token.text)
+		case !parser.hasPrice && isNumber(token.text):
+			err = parser.setPrice(token.text)
+		default:
+			err = fmt.Errorf("unexpected %s after %s (expected ABOVE, BELOW, a #channel or an option like expires=2019-12-31)", token, previous)
+		}
+
+		if err != nil {
+			return err
+		}
+		previous = token.String()
+	}
+
+	if !parser.hasPrice {
+		return fmt.Errorf("expected a price after %s", strings.ToUpper(symbol))
+	}
+	return nil
+}
+
+func (parser *alertArgsParser) parseOption(token alertToken) error {
+	switch token.key {
+	case "price":
+		return parser.setPrice(token.text)
+	case "direction":
+		if !isDirection(token.text) {
+			return fmt.Errorf("the direction must be ABOVE or BELOW, not %s", token.text)
+		}
+		return parser.setDirection(token.text)
+	case "channel":
+		return parser.setChannel(token.text)
+	case "expires":
+		return parser.setExpiry(token.text)
+	case "currency":
+		return parser.setCurrency(token.text)
+	default:
+		return fmt.Errorf("unknown option %s (expected price, direction, channel, expires or currency)", token.key)
+	}
+}
+
+func (parser *alertArgsParser) setPrice(text string) error {
+	if parser.hasPrice {
+		return fmt.Errorf("the price is given more than once")
+	}
+
+	price, err := strconv.ParseFloat(strings.Replace(strings.TrimPrefix(text, "$"), ",", "", -1), 64)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid price", text)
+	}
+	if price <= 0 {
+		return fmt.Errorf("the price must be greater than zero")
+	}
+
+	parser.params.price = price
+	parser.hasPrice = true
+	return nil
+}
+
+func (parser *alertArgsParser) setDirection(text string) error {
+	if parser.hasDirection {
+		return fmt.Errorf("the direction is given more than once")
+	}
+	parser.params.direction = strings.ToUpper(text)
+	parser.hasDirection = true
+	return nil
+}
+
+func (parser *alertArgsParser) setChannel(text string) error {
+	if parser.hasChannel {
+		return fmt.Errorf("the channel is given more than once")
+	}
+
+	channel := strings.TrimSpace(text)
+	if !strings.HasPrefix(channel, "#") {
+		channel = "#" + channel
+	}
+	if len(channel) == 1 {
+		return fmt.Errorf("expected the name of a channel after the #")
+	}
+
+	parser.params.channel = channel
+	parser.hasChannel = true
+	return nil
+}
+
+func (parser *alertArgsParser) setExpiry(text string) error {
+	if parser.hasExpiry {
+		return fmt.Errorf("the expiry date is given more than once")
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", text, parser.now.Location())
+	if err != nil {
+		return fmt.Errorf("%s is not a valid date (use YYYY-MM-DD)", text)
+	}
+
+	// The alert is good until the end of the day
+	date = date.AddDate(0, 0, 1)
+	if !date.After(parser.now) {
+		return fmt.Errorf("the expiry date %s is in the past", text)
+	}
+
+	parser.params.expiresAt = pq.NullTime{Time: date, Valid: true}
+	parser.hasExpiry = true
+	return nil
+}
+
+func (parser *alertArgsParser) setCurrency(text string) error {
+	if parser.hasCurrency {
+		return fmt.Errorf("the currency is given more than once")
+	}
+
+	currency := strings.ToUpper(text)
+	if !validCurrency.MatchString(currency) {
+		return fmt.Errorf("%s is not a currency code like USD or EUR", text)
+	}
+
+	parser.params.currency = currency
+	parser.hasCurrency = true
+	return nil
+}
+
+func isNumber(text string) bool {
+	_, err := strconv.ParseFloat(strings.Replace(strings.TrimPrefix(text, "$"), ",", "", -1), 64)
+	return err == nil
+}
+
+func isDirection(text string) bool {
+	direction := strings.ToUpper(text)
+	return direction == "ABOVE" || direction == "BELOW"
+}
@@ -0,0 +1,98 @@
+package alerts
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAlertCommand(t *testing.T) {
+	now := time.Date(2019, 6, 24, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		text          string
+		wantKind      alertCommandKind
+		wantSymbol    string
+		wantPrice     float64
+		wantDirection string
+		wantChannel   string
+		wantExpiry    bool
+		wantCurrency  string
+		wantErr       string
+	}{
+		{name: "empty lists the alerts", text: "  ", wantKind: commandList},
+		{name: "new", text: "NEW", wantKind: commandNew},
+		{name: "deleteall", text: "deleteall", wantKind: commandDeleteAll},
+		{name: "delete", text: "msft delete", wantKind: commandDelete, wantSymbol: "MSFT"},
+		{name: "symbol and price", text: "MSFT 130", wantKind: commandCreate, wantSymbol: "MSFT", wantPrice: 130, wantDirection: "ABOVE"},
+		{name: "below with channel", text: "msft 130.5 BELOW #myalerts", wantKind: commandCreate, wantSymbol: "MSFT", wantPrice: 130.5, wantDirection: "BELOW", wantChannel: "#myalerts"},
+		{name: "ticker with a dot", text: "BRK.B 215", wantKind: commandCreate, wantSymbol: "BRK.B", wantPrice: 215, wantDirection: "ABOVE"},
+		{name: "ticker that starts with a digit", text: "3M $1,250.00", wantKind: commandCreate, wantSymbol: "3M", wantPrice: 1250, wantDirection: "ABOVE"},
+		{name: "price after the direction", text: "MSFT below 130", wantKind: commandCreate, wantSymbol: "MSFT", wantPrice: 130, wantDirection: "BELOW"},
+		{name: "quoted channel", text: `MSFT 130 "my-alerts"`, wantKind: commandCreate, wantSymbol: "MSFT", wantPrice: 130, wantDirection: "ABOVE", wantChannel: "#my-alerts"},
+		{name: "smart quotes", text: "MSFT 130 “#my-alerts”", wantKind: commandCreate, wantSymbol: "MSFT", wantPrice: 130, wantDirection: "ABOVE", wantChannel: "#my-alerts"},
+		{name: "keyword arguments", text: `MSFT price=130 direction=below channel="#x" expires=2019-12-31`, wantKind: commandCreate, wantSymbol: "MSFT", wantPrice: 130, wantDirection: "BELOW", wantChannel: "#x", wantExpiry: true},
+		{name: "fx pair", text: "eur/usd 1.15 below", wantKind: commandCreate, wantSymbol: "EURUSD", wantPrice: 1.15, wantDirection: "BELOW"},
+		{name: "crypto pair", text: "btcusd 12,000", wantKind: commandCreate, wantSymbol: "BTC-USD", wantPrice: 12000, wantDirection: "ABOVE"},
+		{name: "currency option", text: "SAP.DE 120 currency=usd", wantKind: commandCreate, wantSymbol: "SAP.DE", wantPrice: 120, wantDirection: "ABOVE", wantCurrency: "USD"},
+		{name: "expires today", text: "MSFT 130 expires=2019-06-24", wantKind: commandCreate, wantSymbol: "MSFT", wantPrice: 130, wantDirection: "ABOVE", wantExpiry: true},
+
+		{name: "missing price", text: "MSFT", wantErr: "expected a price after MSFT"},
+		{name: "missing price with a direction", text: "msft below", wantErr: "expected a price after MSFT"},
+		{name: "bad price", text: "MSFT 13o", wantErr: "13o is not a valid price"},
+		{name: "zero price", text: "MSFT 0", wantErr: "the price must be greater than zero"},
+		{name: "two prices", text: "MSFT 130 price=140", wantErr: "the price is given more than once"},
+		{name: "two directions", text: "MSFT 130 above below", wantErr: "the direction is given more than once"},
+		{name: "unexpected word", text: "MSFT 130 sideways", wantErr: "unexpected sideways after 130 (expected ABOVE, BELOW, a #channel or an option like expires=2019-12-31)"},
+		{name: "unknown option", text: "MSFT 130 color=red", wantErr: "unknown option color (expected price, direction, channel, expires or currency)"},
+		{name: "bad currency", text: "MSFT 130 currency=dollars", wantErr: "dollars is not a currency code like USD or EUR"},
+		{name: "bad direction option", text: "MSFT 130 direction=up", wantErr: "the direction must be ABOVE or BELOW, not up"},
+		{name: "bad date", text: "MSFT 130 expires=31/12/2019", wantErr: "31/12/2019 is not a valid date (use YYYY-MM-DD)"},
+		{name: "date in the past", text: "MSFT 130 expires=2019-06-23", wantErr: "the expiry date 2019-06-23 is in the past"},
+		{name: "unterminated quote", text: `MSFT 130 "#my alerts`, wantErr: `the quote in "#my alerts is never closed`},
+		{name: "empty option", text: "MSFT 130 channel=", wantErr: "expected a value after channel="},
+		{name: "option first", text: "price=130", wantErr: "expected a symbol before price=130"},
+		{name: "bad symbol", text: "MS$FT 130", wantErr: "MS$FT is not a valid symbol"},
+		{name: "words after deleteall", text: "deleteall MSFT", wantErr: "unexpected MSFT after deleteall"},
+		{name: "words after delete", text: "MSFT delete now", wantErr: "unexpected now after delete"},
+		{name: "empty channel", text: "MSFT 130 #", wantErr: "expected the name of a channel after the #"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAlertCommand(tt.text, now)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("parseAlertCommand() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseAlertCommand() error = %v", err)
+			}
+
+			if got.kind != tt.wantKind {
+				t.Errorf("parseAlertCommand() kind = %v, want %v", got.kind, tt.wantKind)
+			}
+			if tt.wantSymbol == "" {
+				return
+			}
+
+			params := got.params
+			if params.symbol != tt.wantSymbol {
+				t.Errorf("parseAlertCommand() symbol = %v, want %v", params.symbol, tt.wantSymbol)
+			}
+			if got.kind != commandCreate {
+				return
+			}
+			if params.price != tt.wantPrice || params.direction != tt.wantDirection || params.channel != tt.wantChannel {
+				t.Errorf("parseAlertCommand() = %+v, want price %v direction %v channel %v", params, tt.wantPrice, tt.wantDirection, tt.wantChannel)
+			}
+			if params.currency != tt.wantCurrency {
+				t.Errorf("parseAlertCommand() currency = %v, want %v", params.currency, tt.wantCurrency)
+			}
+			if params.expiresAt.Valid != tt.wantExpiry {
+				t.Errorf("parseAlertCommand() expiresAt = %v, wantExpiry %v", params.expiresAt, tt.wantExpiry)
+			}
+		})
+	}
+}
@@ -4,30 +4,63 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
 	fr "github.com/magmasystems/SlackStockSlashCommand/framework"
 	"github.com/magmasystems/SlackStockSlashCommand/framework/logging"
+	"github.com/magmasystems/SlackStockSlashCommand/metrics"
+	"github.com/magmasystems/SlackStockSlashCommand/preferences"
 	"github.com/magmasystems/SlackStockSlashCommand/slackmessaging"
 	"github.com/magmasystems/SlackStockSlashCommand/stockbot"
 
 	// Need this for postgres
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/nlopes/slack"
 )
 
+// The action ids of the buttons on the alert list
+const (
+	ActionDeleteAlert = "alert_delete"
+	ActionSnoozeAlert = "alert_snooze"
+	ActionEditAlert   = "alert_edit"
+)
+
+// How long the Snooze button silences an alert for
+const snoozeDuration = 24 * time.Hour
+
+// How many symbols are suggested when an alert is set on a symbol that cannot be quoted
+const maxSymbolSuggestions = 3
+
+// How many alerts the list shows. With the title and the footer, that is the 50 blocks that a message can have.
+const maxListedAlerts = 24
+
+var logger = logging.For("alerts")
+
+var (
+	breachChecks        = metrics.CreateCounter("stockbot_breach_checks_total", "The price breach checks, by whether they got the prices.", "result")
+	breachCheckDuration = metrics.CreateHistogram("stockbot_breach_check_duration_seconds", "How long a price breach check takes.", nil)
+	priceBreaches       = metrics.CreateCounter("stockbot_price_breaches_total", "The alerts whose target price was reached.")
+	lastBreachCheck     = metrics.CreateGauge("stockbot_last_breach_check_timestamp_seconds", "When the prices of the alerts were last checked successfully.")
+)
+
 // AlertManager - handles all alerting
 type AlertManager struct {
 	fr.Disposable
 	AlertManagerOps
-	db       *sql.DB
-	config   *config.AppSettings
-	stockBot *stockbot.Stockbot
+	db          *timedDB
+	config      *config.AppSettings
+	stockBot    stockbot.BotOps
+	preferences preferences.PreferenceStoreOps
+	notify      slackmessaging.NotifyFunc
+
+	claimedUsers    sync.Map // the "team/user" keys whose alerts from before OAuth have already been claimed
+	lastBreachCheck int64    // the start of the last successful breach check, in Unix nanoseconds. Accessed atomically.
 }
 
 type quoteAlert struct {
@@ -38,6 +71,9 @@ type quoteAlert struct {
 	price         float64
 	direction     string
 	wasNotified   bool
+	snoozedUntil  pq.NullTime
+	expiresAt     pq.NullTime
+	currency      string // the currency of the target price. Empty means the listing currency of the symbol.
 }
 
 type createAlertParams struct {
@@ -45,8 +81,8 @@ type createAlertParams struct {
 	symbol    string
 	price     float64
 	direction string
-	delete    bool
-	deleteAll bool
+	expiresAt pq.NullTime
+	currency  string
 }
 
 // PriceInfo - holds the price for a stock
@@ -58,240 +94,432 @@ type PriceInfo struct {
 // PriceBreachNotification - a notification that a price limit has been breached
 type PriceBreachNotification struct {
 	SubscriptionID int
+	TeamID         string
 	SlackUserName  string
 	Channel        string
 	Symbol         string
 	TargetPrice    float64
-	CurrentPrice   float64
+	CurrentPrice   float64 // in the currency of the alert
 	Direction      string
+	Currency       string
 }
 
 // AlertManagerOps - defines all operationsn that the AlertManager can do
 type AlertManagerOps interface {
 	CheckForPriceBreaches(stockbot *stockbot.Stockbot, callback func(PriceBreachNotification))
-	HandleQuoteAlert(slashCommand slack.SlashCommand, w http.ResponseWriter)
+	CheckForPriceBreachesOf(stockbot *stockbot.Stockbot, include func(symbol string) bool, callback func(PriceBreachNotification))
+	HandleQuoteAlert(slashCommand slack.SlashCommand, w http.ResponseWriter, log *logging.Logger)
+	HandleInteraction(callback slackmessaging.Interaction, w http.ResponseWriter)
+	HandleViewSubmission(interaction slackmessaging.Interaction, w http.ResponseWriter)
+	DeleteTeamAlerts(teamID string)
 	GetAlertedSymbols() []string
+	GetAlertedSymbolsOf(teamID string, userID string) []string
+	GetAlertedSymbolsByUser() []UserSymbols
+	AdjustForSplits(stockbot *stockbot.Stockbot, now time.Time, callback func(SplitAdjustment))
+	CheckForNewsAlerts(stockbot *stockbot.Stockbot, now time.Time, callback func(NewsNotification))
+	CreateNewsAlert(teamID string, userID string, text string) (string, error)
+	ListNewsAlerts(teamID string, userID string) string
+	DeleteNewsAlert(teamID string, userID string, idText string) string
 	GetPriceBreaches() []PriceBreachNotification
+	LastBreachCheck() time.Time
 	GetQuotesForAlerts(stockbot *stockbot.Stockbot) []PriceInfo
 	SavePrices(prices []PriceInfo) error
 }
 
-// CreateAlertManager - creates and initializes a new AlertManager
-func CreateAlertManager(bot *stockbot.Stockbot) *AlertManager {
+// CreateAlertManager - creates and initializes a new AlertManager.
+// The database is shared with the other stores, so whoever opened it closes it.
+// The preference store can be nil, in which case every user gets the default preferences.
+// The notify function posts the confirmations of the alerts that are created with the modal.
+func CreateAlertManager(appSettings *config.AppSettings, db *sql.DB, bot stockbot.BotOps,
+	prefs preferences.PreferenceStoreOps, notify slackmessaging.NotifyFunc) *AlertManager {
 	alertManager := new(AlertManager)
 
-	db, err := sql.Open("postgres", getDbConnectionInfo())
-	if err != nil {
-		logging.Infoln("Alert Manager: cannot open the postgres database")
-		logging.Infoln(fmt.Sprint(err))
-		time.Sleep(time.Duration(1) * time.Second)
-		logging.Panic(err)
+	// A database that is down now may come up later, and /readyz reports it in the meantime
+	if err := db.Ping(); err != nil {
+		logger.Warn("cannot ping the postgres database", "host", appSettings.Database.Host, "err", err)
 	}
-	logging.Infoln("Alert Manager: was able to open the database.")
-
-	/*
-		logging.Infoln("Alert Manager: About to ping the database")
-		err = db.Ping()
-		if err != nil {
-			logging.Infoln("Alert Manager: cannot ping the postgres database")
-			logging.Infoln(fmt.Sprint(err))
-			logging.Panic(err)
-		}
-		logging.Infoln("Alert Manager: was able to ping the database")
-	*/
 
-	alertManager.db = db
+	alertManager.db = &timedDB{db}
 	alertManager.stockBot = bot
+	alertManager.preferences = prefs
+	alertManager.notify = notify
+	alertManager.config = appSettings
 
-	configMgr := new(config.ConfigManager)
-	alertManager.config = configMgr.Config()
-	logging.Infoln("Alert Manager: fetched the config info")
-
-	logging.Infoln("Alert Manager: returning from creating the alert manager")
+	logger.Info("created the alert manager")
 	return alertManager
 }
 
-// Dispose - clean up resources
+// Dispose - clean up resources. The database belongs to whoever passed it in.
 func (alertManager *AlertManager) Dispose() {
-	if alertManager.db != nil {
-		alertManager.db.Close()
-		alertManager.db = nil
-	}
+	alertManager.db = nil
 }
 
-func getDbConnectionInfo() string {
-	configMgr := new(config.ConfigManager)
-	appSettings := configMgr.Config()
-
-	psqlInfo := fmt.Sprintf("host=%s port=%d dbname=%s", appSettings.Database.Host, appSettings.Database.Port, appSettings.Database.DbName)
-	if appSettings.Database.User != "" {
-		psqlInfo += fmt.Sprintf(" user=%s", appSettings.Database.User)
+// preferencesOf - the preferences of the user who owns an alert
+func (alertManager *AlertManager) preferencesOf(teamID string, userID string) preferences.Preferences {
+	if alertManager.preferences == nil {
+		return preferences.Default()
 	}
-	if appSettings.Database.Password != "" {
-		psqlInfo += fmt.Sprintf(" password=%s", appSettings.Database.Password)
+	return alertManager.preferences.Get(teamID, userID)
+}
+
+// claimAlertsFromBeforeOAuth - moves the alerts that a user created before OAuth, which have an empty team,
+// into the team that the user is in now. Every query on the alerts of a user filters on the team, so without this
+// those alerts could never be listed, edited or deleted again. It only goes to the database once per user.
+func (alertManager *AlertManager) claimAlertsFromBeforeOAuth(teamID string, userID string) {
+	if teamID == "" {
+		return
 	}
-	if appSettings.Database.SSL {
-		psqlInfo += fmt.Sprintf(" sslmode=require")
-	} else {
-		psqlInfo += fmt.Sprintf(" sslmode=disable")
+	key := teamID + "/" + userID
+	if _, claimed := alertManager.claimedUsers.Load(key); claimed {
+		return
 	}
 
-	// [host=slackstockbot.cfaf3ksbohge.us-east-2.rds.amazonaws.com port=5432 dbname=slackstockbot sslmode=disable user=magmasystems password=magma123]
-	logging.Infof("Alert Manager: The connection info is [%s]\n", psqlInfo)
-
-	return psqlInfo
+	sqlStatement := `UPDATE slackstockbot.alertsubscription SET teamid = $1 WHERE teamid = '' AND slackuser = $2;`
+	result, err := alertManager.db.Exec(sqlStatement, teamID, userID)
+	if err != nil {
+		logger.Warn("cannot claim the alerts from before OAuth", "team", teamID, "user", userID, "err", err)
+		return
+	}
+	alertManager.claimedUsers.Store(key, true)
+	if n, err := result.RowsAffected(); err == nil && n > 0 {
+		logger.Info("claimed the alerts from before OAuth", "team", teamID, "user", userID, "alerts", n)
+	}
 }
 
 // HandleQuoteAlert - parses and dispatches a /quote-alert command from Slack
-func (alertManager *AlertManager) HandleQuoteAlert(slashCommand slack.SlashCommand, writer http.ResponseWriter) {
+// The log carries the request id of the slash command.
+func (alertManager *AlertManager) HandleQuoteAlert(slashCommand slack.SlashCommand, writer http.ResponseWriter, log *logging.Logger) {
 	outputText := ""
-	args := strings.Split(strings.Trim(slashCommand.Text, " "), " ")
-	logging.Infof("Alert Manager: Got new Quote Alert with the args [%s]\n", fmt.Sprint(args))
-
-	var params *createAlertParams
-
-	// If no args were passed, then we just send back a list of all of the alerts that a user has
-	if args[0] != "" {
-		// syntax: /quotealert symbol price [below] [delete]
-		params = &createAlertParams{"", "", 0.0, "ABOVE", false, false}
-		for i := 0; i < len(args); i = i + 1 {
-			param := strings.ToLower(args[i])
-
-			if param == "deleteall" {
-				params.deleteAll = true
-			} else if param == "delete" {
-				params.delete = true
-			} else if param == "above" || param == "below" {
-				params.direction = strings.ToUpper(param)
-			} else if strings.ContainsAny(args[i], "0123456789") {
-				params.price, _ = strconv.ParseFloat(param, 32)
-			} else if param[0] == '#' {
-				params.channel = args[i] // if the arg starts with #, assume it is the name of a channel to send the notification to
-			} else {
-				params.symbol = param
-			}
-		}
+	log.Debug("got a quote alert", "text", slashCommand.Text)
+	alertManager.claimAlertsFromBeforeOAuth(slashCommand.TeamID, slashCommand.UserID)
+
+	// "until 12/31" is the end of the day where the user is
+	location := alertManager.preferencesOf(slashCommand.TeamID, slashCommand.UserID).Location()
+	command, err := parseAlertCommand(slashCommand.Text, time.Now().In(location))
+	if err != nil {
+		outputText = fmt.Sprintf("Sorry, %s. Type `/quote-alert help` to see some examples.", err.Error())
+		log.Info("the alert command is invalid", "text", slashCommand.Text, "err", err)
+		slackmessaging.WriteResponse(writer, outputText)
+		return
 	}
+	log.Debug("parsed the alert command", "params", fmt.Sprint(command.params))
 
-	logging.Infof("Alert Manager: the alert params are [%s]\n", fmt.Sprint(params))
+	switch command.kind {
+	case commandList:
+		// Send back the list of alerts, with buttons to manage them
+		slackmessaging.WriteBlockResponse(writer, alertManager.listAllAlerts(slashCommand.TeamID, slashCommand.UserID))
+		return
 
-	if params != nil {
-		if params.deleteAll {
-			alertManager.deleteAllAlerts(slashCommand.UserID)
-			outputText = fmt.Sprintf("All alerts deleted for user %s", slashCommand.UserName)
-		} else if params.delete {
-			alertManager.deleteAlert(slashCommand.UserID, params)
-			outputText = fmt.Sprintf("Alert deleted for user %s", slashCommand.UserName)
+	case commandNew:
+		// Open a modal with all of the fields of an alert
+		if err = alertManager.openAlertModal(slashCommand.TeamID, slashCommand.UserID, slashCommand.TriggerID, nil); err != nil {
+			log.Warn("cannot open the alert modal", "err", err)
+			slackmessaging.WriteResponse(writer, "The alert dialog is not available. Type `/quote-alert help` to create an alert with a command.")
+			return
+		}
+		writer.WriteHeader(http.StatusOK)
+		return
+
+	case commandDeleteAll:
+		alertManager.deleteAllAlerts(slashCommand.TeamID, slashCommand.UserID)
+		outputText = fmt.Sprintf("All alerts deleted for user %s", slashCommand.UserName)
+
+	case commandDelete:
+		alertManager.deleteAlert(slashCommand.TeamID, slashCommand.UserID, command.params)
+		outputText = fmt.Sprintf("Alert on %s deleted for user %s", command.params.symbol, slashCommand.UserName)
+
+	case commandCreate:
+		if command.params.channel == "" {
+			command.params.channel = alertManager.preferencesOf(slashCommand.TeamID, slashCommand.UserID).DefaultChannel
+		}
+		newID, err := alertManager.insertNewAlert(slashCommand.TeamID, slashCommand.UserID, command.params)
+		if err != nil {
+			outputText = err.Error() // maybe the user request a symbol that is not a stock
 		} else {
-			newID, err := alertManager.insertNewAlert(slashCommand.UserID, params)
-			if err != nil {
-				outputText = err.Error() // maybe the user request a symbol that is not a stock
-			} else {
-				outputText = fmt.Sprintf("Alert %s Created for user %s", newID, slashCommand.UserName)
-			}
+			outputText = fmt.Sprintf("Alert %s Created for user %s", newID, slashCommand.UserName)
+			log.Info("created the alert", "id", newID, "symbol", command.params.symbol)
 		}
-	} else {
-		outputText = alertManager.listAllAlerts(slashCommand.UserID)
 	}
 
 	// Send the response back to Slack
-	logging.Infoln(outputText)
+	log.Debug("answering the alert command", "text", outputText)
 	slackmessaging.WriteResponse(writer, outputText)
 }
 
-func (alertManager *AlertManager) listAllAlerts(userID string) string {
-	q := new(quoteAlert)
-	outputText := ""
+func (alertManager *AlertManager) listAllAlerts(teamID string, userID string) slack.Message {
+	format := slackmessaging.SlackMessageFormat{Title: "Your Price Alerts"}
+	prefs := alertManager.preferencesOf(teamID, userID)
 
-	sqlStatement := `SELECT id, slackuser, channel, symbol, targetprice, wasnotified, direction
+	sqlStatement := `SELECT id, slackuser, channel, symbol, targetprice, wasnotified, direction, snoozeduntil, expiresat, currency
 	FROM slackstockbot.alertsubscription
-	WHERE slackuser = $1`
-
-	rows, err := alertManager.db.Query(sqlStatement, userID)
-	defer rows.Close()
+	WHERE teamid = $1 AND slackuser = $2
+	ORDER BY symbol`
 
+	rows, err := alertManager.db.Query(sqlStatement, teamID, userID)
 	if err != nil {
-		logging.Infoln(err.Error())
-		return ""
+		logger.Error("cannot list the alerts", "err", err)
+		format.Text = "Your alerts cannot be retrieved right now"
+		return format.ToBlock()
 	}
+	defer rows.Close()
 
 	for rows.Next() {
-		err = rows.Scan(&q.id, &q.slackUserName, &q.channel, &q.symbol, &q.price, &q.wasNotified, &q.direction)
+		q := new(quoteAlert)
+		err = rows.Scan(&q.id, &q.slackUserName, &q.channel, &q.symbol, &q.price, &q.wasNotified, &q.direction, &q.snoozedUntil, &q.expiresAt, &q.currency)
 		if err != nil {
 			panic(err)
 		}
-		outputText += fmt.Sprintf("%s\t%3.2f (%s)\n", q.symbol, q.price, q.direction)
+		format.Items = append(format.Items, alertListItem(q, prefs))
+	}
+
+	if len(format.Items) == 0 {
+		format.Text = "You have no alerts. Type `/quote-alert help` to see how to create one."
+	}
+	limitAlertList(&format)
+
+	return format.ToBlock()
+}
+
+// limitAlertList - a message can have at most 50 blocks, and every alert takes two of them,
+// so the alerts after the first maxListedAlerts are only counted in the footer
+func limitAlertList(format *slackmessaging.SlackMessageFormat) {
+	if more := len(format.Items) - maxListedAlerts; more > 0 {
+		format.Items = format.Items[:maxListedAlerts]
+		format.Footer = fmt.Sprintf("...and %d more", more)
+	}
+}
+
+// alertListItem - a line in the alert list, with the buttons that manage the alert
+func alertListItem(q *quoteAlert, prefs preferences.Preferences) slackmessaging.SlackMessageItem {
+	text := fmt.Sprintf("*%s*\t%s (%s)", q.symbol, prefs.FormatPrice(q.price), q.direction)
+	if q.currency != "" {
+		text = fmt.Sprintf("*%s*\t%s %s (%s)", q.symbol, prefs.FormatPrice(q.price), q.currency, q.direction)
+	}
+	if strings.HasPrefix(q.channel, "#") {
+		text += " to " + q.channel
+	} else if q.channel != "" {
+		text += " to <#" + q.channel + ">" // channels picked in the alert modal are stored by id
+	}
+	if q.wasNotified {
+		text += " - _triggered_"
+	} else if q.expiresAt.Valid && q.expiresAt.Time.Before(time.Now()) {
+		text += " - _expired_"
+	} else if q.snoozedUntil.Valid && q.snoozedUntil.Time.After(time.Now()) {
+		text += " - _snoozed until " + prefs.FormatTime(q.snoozedUntil.Time) + "_"
+	} else if q.expiresAt.Valid {
+		text += " - _until " + lastDay(q.expiresAt.Time, prefs.Location()).Format("Jan 2") + "_"
+	}
+
+	id := strconv.Itoa(q.id)
+	return slackmessaging.SlackMessageItem{
+		BlockID: "alert_" + id,
+		Text:    text,
+		Buttons: []slackmessaging.SlackButton{
+			{ActionID: ActionEditAlert, Value: id, Text: "Edit"},
+			{ActionID: ActionSnoozeAlert, Value: id, Text: "Snooze 1 day"},
+			{ActionID: ActionDeleteAlert, Value: id, Text: "Delete", Style: "danger", Confirm: "Delete the alert on " + q.symbol + "?"},
+		},
+	}
+}
+
+// HandleInteraction - dispatches a click on one of the buttons in the alert list
+func (alertManager *AlertManager) HandleInteraction(callback slackmessaging.Interaction, writer http.ResponseWriter) {
+	// Slack wants an acknowledgement within 3 seconds. The header is only sent when the handler returns,
+	// so the actions are done in the background. The updated list goes back through the response_url.
+	writer.WriteHeader(http.StatusOK)
+	go alertManager.doBlockActions(callback)
+}
+
+// doBlockActions - deletes, snoozes or edits the alerts whose buttons were clicked, and redraws the list
+func (alertManager *AlertManager) doBlockActions(callback slackmessaging.Interaction) {
+	teamID := callback.Team.ID
+	userID := callback.User.ID
+
+	// The database calls panic when they fail. Out here, nothing else would recover from that.
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("cannot do the action on the alert", "team", teamID, "user", userID, "err", r)
+		}
+	}()
+
+	alertManager.claimAlertsFromBeforeOAuth(teamID, userID)
+
+	for _, action := range callback.ActionCallback.BlockActions {
+		logger.Debug("got an action on an alert", "action", action.ActionID, "alert", action.Value, "user", userID)
+
+		id, err := strconv.Atoi(action.Value)
+		if err != nil {
+			logger.Warn("the alert id is invalid", "alert", action.Value)
+			continue
+		}
+
+		switch action.ActionID {
+		case ActionDeleteAlert:
+			alertManager.deleteAlertByID(teamID, userID, id)
+		case ActionSnoozeAlert:
+			alertManager.snoozeAlert(teamID, userID, id, time.Now().Add(snoozeDuration))
+		case ActionEditAlert:
+			alertManager.editAlert(callback.TriggerID, callback.ResponseURL, teamID, userID, id)
+			continue
+		default:
+			logger.Warn("the action is unknown", "action", action.ActionID)
+			continue
+		}
+
+		// Redraw the list so that it shows the change
+		if err = slackmessaging.RespondToInteraction(callback.ResponseURL, alertManager.listAllAlerts(teamID, userID), true); err != nil {
+			logger.Warn("cannot redraw the alert list", "err", err)
+		}
+	}
+}
+
+// editAlert - opens the alert modal on an existing alert. Without a bot token, the user gets instructions instead.
+func (alertManager *AlertManager) editAlert(triggerID string, responseURL string, teamID string, userID string, id int) {
+	q := alertManager.getAlertByID(teamID, userID, id)
+	if q == nil {
+		return
 	}
 
-	return outputText
+	if err := alertManager.openAlertModal(teamID, userID, triggerID, q); err == nil {
+		return
+	}
+
+	format := slackmessaging.SlackMessageFormat{
+		Text: fmt.Sprintf("To change this alert, type `/quote-alert %s <new price> %s`", q.symbol, q.direction),
+	}
+	if err := slackmessaging.RespondToInteraction(responseURL, format.ToBlock(), false); err != nil {
+		logger.Warn("cannot send the edit instructions", "err", err)
+	}
 }
 
-func (alertManager *AlertManager) getAlert(userID string, params *createAlertParams) *quoteAlert {
+func (alertManager *AlertManager) getAlert(teamID string, userID string, params *createAlertParams) *quoteAlert {
 	sqlStatement := `SELECT id, slackuser, channel, symbol, targetprice, wasnotified, direction
 	FROM slackstockbot.alertsubscription
-	WHERE slackuser = $1 AND symbol = $2 AND direction = $3`
+	WHERE teamid = $1 AND slackuser = $2 AND symbol = $3 AND direction = $4`
 
-	logging.Infof("AlertManager.getAlert: %s\n", sqlStatement)
-	row := alertManager.db.QueryRow(sqlStatement, userID, params.symbol, params.direction)
-	logging.Infof("AlertManager.getAlert: returned with row [%+v]\n", row)
+	row := alertManager.db.QueryRow(sqlStatement, teamID, userID, params.symbol, params.direction)
 
 	q := new(quoteAlert)
 
 	switch err := row.Scan(&q.id, &q.slackUserName, &q.channel, &q.symbol, &q.price, &q.wasNotified, &q.direction); err {
 	case sql.ErrNoRows:
-		logging.Infoln("AlertManager.getAlert: no rows returned. Returning nil")
 		return nil
 	case nil:
-		logging.Infoln("AlertManager.getAlert: no errors returned. Returning the alert")
 		return q
 	default:
-		logging.Fatal(err)
+		logger.Error("cannot get the alert", "symbol", params.symbol, "err", err)
+		panic(err)
+	}
+}
+
+func (alertManager *AlertManager) getAlertByID(teamID string, userID string, id int) *quoteAlert {
+	sqlStatement := `SELECT id, slackuser, channel, symbol, targetprice, wasnotified, direction, snoozeduntil, expiresat, currency
+	FROM slackstockbot.alertsubscription
+	WHERE teamid = $1 AND slackuser = $2 AND id = $3`
+
+	q := new(quoteAlert)
+	row := alertManager.db.QueryRow(sqlStatement, teamID, userID, id)
+
+	switch err := row.Scan(&q.id, &q.slackUserName, &q.channel, &q.symbol, &q.price, &q.wasNotified, &q.direction, &q.snoozedUntil, &q.expiresAt, &q.currency); err {
+	case sql.ErrNoRows:
+		return nil
+	case nil:
+		return q
+	default:
+		logger.Error("cannot get the alert", "id", id, "err", err)
 		panic(err)
 	}
 }
 
-func (alertManager *AlertManager) insertNewAlert(userID string, params *createAlertParams) (string, error) {
-	logging.Infof("AlertManager.insertNewAlert: Getting alerts for [userID %s, symbol %s]", userID, params.symbol)
-	quoteAlert := alertManager.getAlert(userID, params)
-	logging.Infof("AlertManager.insertNewAlert: Returned with quoteAlert %+v", quoteAlert)
+// checkAlert - makes sure that the alert can be checked: that the symbol can be quoted, and that there is an exchange rate
+// into the currency of the alert. The field is the input of the alert modal that the problem belongs to.
+func (alertManager *AlertManager) checkAlert(params *createAlertParams) (field string, err error) {
+	// An alert in another currency is only any good if we can get the exchange rate
+	if params.currency != "" {
+		if _, err = alertManager.stockBot.ConvertPrice(params.symbol, params.price, params.currency); err != nil {
+			return modalCurrency, err
+		}
+	}
+
+	// See if the symbol is a valid stock. Symbols that have been quoted before are in the reference data,
+	// so only the new ones cost a call to fetch the current price.
+	if alertManager.stockBot.SymbolInfo(params.symbol) == nil {
+		quoteInfo := alertManager.stockBot.QuoteSingle(params.symbol)
+		if len(quoteInfo) == 0 || quoteInfo[0].LastPrice == 0 {
+			return modalSymbol, alertManager.invalidSymbolError(params.symbol)
+		}
+	}
+	return "", nil
+}
+
+func (alertManager *AlertManager) insertNewAlert(teamID string, userID string, params *createAlertParams) (string, error) {
+	if _, err := alertManager.checkAlert(params); err != nil {
+		return "", err
+	}
+	return alertManager.saveAlert(teamID, userID, params), nil
+}
+
+// saveAlert - stores an alert that has been checked. An alert on a symbol and direction that the user already has replaces it.
+func (alertManager *AlertManager) saveAlert(teamID string, userID string, params *createAlertParams) string {
+	quoteAlert := alertManager.getAlert(teamID, userID, params)
 
 	if quoteAlert != nil {
 		// The record already exists. Just update the fields
-		sqlStatement := `UPDATE slackstockbot.alertsubscription SET targetprice = $1, direction = $2 WHERE id = $3`
-		res, err := alertManager.db.Exec(sqlStatement, params.price, params.direction, quoteAlert.id)
+		sqlStatement := `UPDATE slackstockbot.alertsubscription SET targetprice = $1, direction = $2, expiresat = $3, currency = $4, targetsetat = now() WHERE id = $5`
+		res, err := alertManager.db.Exec(sqlStatement, params.price, params.direction, params.expiresAt, params.currency, quoteAlert.id)
 		if err != nil {
-			logging.Fatal(err)
+			logger.Error("cannot update the alert", "id", quoteAlert.id, "err", err)
 			panic(err)
 		}
 
 		rowsUpdated, _ := res.RowsAffected()
 		if rowsUpdated == 1 {
-			return strconv.Itoa(quoteAlert.id), nil
+			return strconv.Itoa(quoteAlert.id)
 		}
-		return "0", nil
-	}
-
-	// See if the symbol is a valid stock by trying to fetch the current price
-	quoteInfo := alertManager.stockBot.QuoteSingle(params.symbol)
-	if len(quoteInfo) == 0 || quoteInfo[0].LastPrice == 0 {
-		return "", errors.New("The symbol is invalid")
+		return "0"
 	}
 
 	// https://www.calhoun.io/inserting-records-into-a-postgresql-database-with-gos-database-sql-package/
 	sqlStatement := `
-INSERT INTO slackstockbot.alertsubscription (slackuser, channel, symbol, targetprice, wasnotified, direction)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO slackstockbot.alertsubscription (teamid, slackuser, channel, symbol, targetprice, wasnotified, direction, expiresat, currency)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING id`
 	id := 0
-	logging.Infof("AlertManager.insertNewAlert: %s\n", sqlStatement)
-	err := alertManager.db.QueryRow(sqlStatement, userID, params.channel, strings.ToUpper(params.symbol), params.price, false, params.direction).Scan(&id)
-	logging.Infof("AlertManager.insertNewAlert: QueryRow returned with err [%s]\n", fmt.Sprint(err))
+	err := alertManager.db.QueryRow(sqlStatement, teamID, userID, params.channel, params.symbol, params.price, false, params.direction, params.expiresAt, params.currency).Scan(&id)
 	if err != nil {
-		logging.Panic(err)
+		logger.Error("cannot insert the alert", "symbol", params.symbol, "err", err)
+		panic(err)
+	}
+
+	return strconv.Itoa(id)
+}
+
+// invalidSymbolError - says that the symbol cannot be quoted, and suggests the closest symbols that can
+func (alertManager *AlertManager) invalidSymbolError(symbol string) error {
+	suggestions := alertManager.stockBot.SuggestSymbols(symbol, maxSymbolSuggestions)
+	if len(suggestions) == 0 {
+		return errors.New("The symbol is invalid")
+	}
+
+	names := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		names[i] = fmt.Sprintf("%s (%s)", suggestion.Symbol, suggestion.Name)
 	}
+	return fmt.Errorf("The symbol %s is invalid. Did you mean %s?", symbol, strings.Join(names, ", "))
+}
 
-	logging.Infof("AlertManager.insertNewAlert: returning id %d\n", id)
-	return strconv.Itoa(id), nil
+// updateAlert - changes every field of an alert that was edited in the alert modal, and re-arms it.
+// Returns false if the user has no such alert, which happens when it was deleted while the modal was open.
+func (alertManager *AlertManager) updateAlert(teamID string, userID string, id int, params *createAlertParams) bool {
+	sqlStatement := `UPDATE slackstockbot.alertsubscription
+	SET channel = $1, symbol = $2, targetprice = $3, direction = $4, expiresat = $5, currency = $6, wasnotified = false, targetsetat = now()
+	WHERE teamid = $7 AND slackuser = $8 AND id = $9`
+	res, err := alertManager.db.Exec(sqlStatement, params.channel, params.symbol, params.price, params.direction, params.expiresAt, params.currency, teamID, userID, id)
+	if err != nil {
+		panic(err)
+	}
+	rowsUpdated, _ := res.RowsAffected()
+	return rowsUpdated == 1
 }
 
 func (alertManager *AlertManager) setWasNotified(id int) {
@@ -299,36 +527,100 @@ func (alertManager *AlertManager) setWasNotified(id int) {
 	alertManager.db.Exec(sqlStatement, id)
 }
 
-func (alertManager *AlertManager) deleteAllAlerts(userID string) {
-	sqlStatement := `DELETE FROM slackstockbot.alertsubscription WHERE slackuser = $1;`
-	_, err := alertManager.db.Exec(sqlStatement, userID)
+func (alertManager *AlertManager) deleteAllAlerts(teamID string, userID string) {
+	sqlStatement := `DELETE FROM slackstockbot.alertsubscription WHERE teamid = $1 AND slackuser = $2;`
+	_, err := alertManager.db.Exec(sqlStatement, teamID, userID)
 	if err != nil {
 		panic(err)
 	}
 }
 
-func (alertManager *AlertManager) deleteAlert(userID string, params *createAlertParams) string {
-	sqlStatement := `DELETE FROM slackstockbot.alertsubscription WHERE slackuser = $1 AND symbol = $2;`
-	_, err := alertManager.db.Exec(sqlStatement, userID, params.symbol)
+func (alertManager *AlertManager) deleteAlert(teamID string, userID string, params *createAlertParams) string {
+	sqlStatement := `DELETE FROM slackstockbot.alertsubscription WHERE teamid = $1 AND slackuser = $2 AND symbol = $3;`
+	_, err := alertManager.db.Exec(sqlStatement, teamID, userID, params.symbol)
 	if err != nil {
 		panic(err)
 	}
 	return "alert deleted"
 }
 
+func (alertManager *AlertManager) deleteAlertByID(teamID string, userID string, id int) {
+	// The team and user ids are part of the key so that a user can only delete their own alerts
+	sqlStatement := `DELETE FROM slackstockbot.alertsubscription WHERE teamid = $1 AND slackuser = $2 AND id = $3;`
+	_, err := alertManager.db.Exec(sqlStatement, teamID, userID, id)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (alertManager *AlertManager) snoozeAlert(teamID string, userID string, id int, until time.Time) {
+	sqlStatement := `UPDATE slackstockbot.alertsubscription SET snoozeduntil = $1 WHERE teamid = $2 AND slackuser = $3 AND id = $4;`
+	_, err := alertManager.db.Exec(sqlStatement, until, teamID, userID, id)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// DeleteTeamAlerts - removes the alerts of every user in a workspace after the app was uninstalled from it
+func (alertManager *AlertManager) DeleteTeamAlerts(teamID string) {
+	sqlStatement := `DELETE FROM slackstockbot.alertsubscription WHERE teamid = $1;`
+	result, err := alertManager.db.Exec(sqlStatement, teamID)
+	if err != nil {
+		logger.Error("cannot delete the alerts of the team", "team", teamID, "err", err)
+		return
+	}
+
+	count, _ := result.RowsAffected()
+	logger.Info("deleted the alerts of the team", "team", teamID, "count", count)
+
+	if _, err := alertManager.db.Exec(`DELETE FROM slackstockbot.newsalert WHERE teamid = $1;`, teamID); err != nil {
+		logger.Error("cannot delete the news alerts of the team", "team", teamID, "err", err)
+	}
+}
+
 // CheckForPriceBreaches - gets called by the application at periodic intervals to check for price breaches
 func (alertManager *AlertManager) CheckForPriceBreaches(stockbot *stockbot.Stockbot, callback func(PriceBreachNotification)) {
+	alertManager.CheckForPriceBreachesOf(stockbot, nil, callback)
+}
+
+// CheckForPriceBreachesOf - checks the alerts on the symbols that the filter includes, or on all of them if it is nil.
+// This lets the crypto alerts be checked while the stock exchange is closed.
+func (alertManager *AlertManager) CheckForPriceBreachesOf(stockbot *stockbot.Stockbot, include func(symbol string) bool, callback func(PriceBreachNotification)) {
+	started := time.Now()
+	defer breachCheckDuration.ObserveSince(started)
+
 	// Get the latest quotes
-	prices := alertManager.GetQuotesForAlerts(stockbot)
+	symbols := alertManager.GetAlertedSymbols()
+	if include != nil {
+		var included []string
+		for _, symbol := range symbols {
+			if include(symbol) {
+				included = append(included, symbol)
+			}
+		}
+		symbols = included
+	}
+
+	if len(symbols) == 0 {
+		alertManager.breachCheckDone(started, 0)
+		return
+	}
+
+	prices := alertManager.getQuotesForSymbols(stockbot, symbols)
 	if prices == nil {
+		breachChecks.Inc("failed")
 		return
 	}
 
 	// Save the prices to the database
-	alertManager.SavePrices(prices)
+	if err := alertManager.SavePrices(prices); err != nil {
+		breachChecks.Inc("failed")
+		return
+	}
 
 	// Check for any price breaches
 	notifications := alertManager.GetPriceBreaches()
+	alertManager.breachCheckDone(started, len(notifications))
 
 	// Go through all of the price breaches and notify the Slack user
 	for _, notification := range notifications {
@@ -340,9 +632,32 @@ func (alertManager *AlertManager) CheckForPriceBreaches(stockbot *stockbot.Stock
 	}
 }
 
+// breachCheckDone - counts a check that got the prices of all of the alerted symbols
+func (alertManager *AlertManager) breachCheckDone(started time.Time, breaches int) {
+	breachChecks.Inc("ok")
+	priceBreaches.Add(float64(breaches))
+	lastBreachCheck.SetToTime(started)
+	atomic.StoreInt64(&alertManager.lastBreachCheck, started.UnixNano())
+}
+
+// LastBreachCheck - when the last successful breach check started, or the zero time if none has succeeded
+func (alertManager *AlertManager) LastBreachCheck() time.Time {
+	if at := atomic.LoadInt64(&alertManager.lastBreachCheck); at != 0 {
+		return time.Unix(0, at)
+	}
+	return time.Time{}
+}
+
 // GetQuotesForAlerts - gets the current prices for all alertable stocks
 func (alertManager *AlertManager) GetQuotesForAlerts(stockbot *stockbot.Stockbot) []PriceInfo {
-	symbols := alertManager.GetAlertedSymbols()
+	return alertManager.getQuotesForSymbols(stockbot, alertManager.GetAlertedSymbols())
+}
+
+// getQuotesForSymbols - gets the current prices of some of the alerted symbols
+func (alertManager *AlertManager) getQuotesForSymbols(stockbot *stockbot.Stockbot, symbols []string) []PriceInfo {
+	if len(symbols) == 0 {
+		return nil
+	}
 
 	go func() {
 		stockbot.QuoteAsync(symbols)
@@ -369,11 +684,13 @@ func (alertManager *AlertManager) GetAlertedSymbols() []string {
 	var symbols []string
 	var symbol string
 
-	sqlStatement := `SELECT DISTINCT symbol FROM slackstockbot.alertsubscription ORDER BY symbol;`
+	sqlStatement := `SELECT DISTINCT symbol FROM slackstockbot.alertsubscription
+	WHERE expiresat IS NULL OR expiresat > now()
+	ORDER BY symbol;`
 
 	rows, err := alertManager.db.Query(sqlStatement)
 	if err != nil {
-		logging.Infoln(err.Error())
+		logger.Error("cannot get the alerted symbols", "err", err)
 		return symbols
 	}
 
@@ -381,31 +698,98 @@ func (alertManager *AlertManager) GetAlertedSymbols() []string {
 	for rows.Next() {
 		err = rows.Scan(&symbol)
 		if err != nil {
-			logging.Fatal(err)
+			logger.Error("cannot read an alerted symbol", "err", err)
 			panic(err)
 		}
 		symbols = append(symbols, symbol)
 	}
 
-	logging.Infoln("The alerted symbols are:")
-	logging.Infoln(fmt.Sprint(symbols))
+	logger.Debug("got the alerted symbols", "symbols", strings.Join(symbols, ","))
+
+	return symbols
+}
+
+// UserSymbols - the symbols that one user has alerts on
+type UserSymbols struct {
+	TeamID  string
+	UserID  string
+	Symbols []string
+}
+
+// GetAlertedSymbolsOf - the symbols that a user has alerts on that have not expired
+func (alertManager *AlertManager) GetAlertedSymbolsOf(teamID string, userID string) []string {
+	var symbols []string
+	alertManager.claimAlertsFromBeforeOAuth(teamID, userID)
+
+	sqlStatement := `SELECT DISTINCT symbol FROM slackstockbot.alertsubscription
+	WHERE teamid = $1 AND slackuser = $2 AND (expiresat IS NULL OR expiresat > now())
+	ORDER BY symbol;`
 
+	rows, err := alertManager.db.Query(sqlStatement, teamID, userID)
+	if err != nil {
+		logger.Error("cannot get the alerted symbols of the user", "user", userID, "err", err)
+		return symbols
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var symbol string
+		if err = rows.Scan(&symbol); err != nil {
+			logger.Error("cannot read an alerted symbol", "err", err)
+			return symbols
+		}
+		symbols = append(symbols, symbol)
+	}
 	return symbols
 }
 
+// GetAlertedSymbolsByUser - the symbols of every user who has alerts that have not expired
+func (alertManager *AlertManager) GetAlertedSymbolsByUser() []UserSymbols {
+	var users []UserSymbols
+
+	sqlStatement := `SELECT DISTINCT teamid, slackuser, symbol FROM slackstockbot.alertsubscription
+	WHERE expiresat IS NULL OR expiresat > now()
+	ORDER BY teamid, slackuser, symbol;`
+
+	rows, err := alertManager.db.Query(sqlStatement)
+	if err != nil {
+		logger.Error("cannot get the alerted symbols of the users", "err", err)
+		return users
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var teamID, userID, symbol string
+		if err = rows.Scan(&teamID, &userID, &symbol); err != nil {
+			logger.Error("cannot read an alerted symbol", "err", err)
+			return users
+		}
+
+		// The rows of a user are next to each other
+		if n := len(users); n > 0 && users[n-1].TeamID == teamID && users[n-1].UserID == userID {
+			users[n-1].Symbols = append(users[n-1].Symbols, symbol)
+		} else {
+			users = append(users, UserSymbols{TeamID: teamID, UserID: userID, Symbols: []string{symbol}})
+		}
+	}
+	return users
+}
+
 // SavePrices - saves a list of updated quotes in the database
 func (alertManager *AlertManager) SavePrices(prices []PriceInfo) error {
 	sqlStatement := `DELETE FROM slackstockbot.stockprice;`
 	_, err := alertManager.db.Exec(sqlStatement)
 	if err != nil {
-		log.Fatalln(err)
+		logger.Error("cannot clear the prices", "err", err)
+		return err
 	}
 
 	// Insert multiple values
 	sqlStatement = "INSERT INTO slackstockbot.stockprice (symbol, price, time) VALUES "
 	for _, pi := range prices {
 		if pi.Price > 0 {
-			sqlStatement += fmt.Sprintf("('%s', %3.2f, current_date),", strings.ToUpper(pi.Symbol), pi.Price)
+			// FX rates and small coins need more than two decimals
+			sqlStatement += fmt.Sprintf("('%s', %.6f, current_date),", strings.ToUpper(pi.Symbol), pi.Price)
 		}
 	}
 
@@ -413,9 +797,11 @@ func (alertManager *AlertManager) SavePrices(prices []PriceInfo) error {
 	sqlStatement = strings.TrimRight(sqlStatement, ",") + ";"
 
 	_, err = alertManager.db.Exec(sqlStatement)
-
-	logging.Infoln("Saving the new prices to the database:")
-	logging.Infoln(sqlStatement)
+	if err != nil {
+		logger.Error("cannot save the prices", "err", err)
+	} else {
+		logger.Debug("saved the prices", "count", len(prices))
+	}
 
 	return err
 }
@@ -426,20 +812,36 @@ func (alertManager *AlertManager) GetPriceBreaches() []PriceBreachNotification {
 	q := PriceBreachNotification{}
 
 	// This SQL will compare all of the alerts against the list of current quotes, and identify those alerts
-	// which have price breaches in either direction.
-	sqlStatement := `SELECT a.id, a.slackuser, a.channel, a.symbol, a.targetprice, a.direction, p.price 
+	// which have price breaches in either direction. The prices are in the listing currency of the symbols,
+	// so the alerts in other currencies are all returned, and are compared after the price is converted.
+	sqlStatement := `SELECT a.id, a.teamid, a.slackuser, a.channel, a.symbol, a.targetprice, a.direction, a.currency, p.price 
 	FROM slackstockbot.alertsubscription a, slackstockbot.stockprice p
 	WHERE a.wasnotified = false AND a.symbol = p.symbol AND p.price > 0 AND
-	      ( (a.direction = 'ABOVE' AND p.price >= a.targetprice) OR (a.direction = 'BELOW' AND p.price <= a.targetprice) );`
+	      (a.snoozeduntil IS NULL OR a.snoozeduntil < now()) AND
+	      (a.expiresat IS NULL OR a.expiresat > now()) AND
+	      ( a.currency <> '' OR
+	        (a.direction = 'ABOVE' AND p.price >= a.targetprice) OR (a.direction = 'BELOW' AND p.price <= a.targetprice) );`
 
 	rows, err := alertManager.db.Query(sqlStatement)
 	defer rows.Close()
 	for rows.Next() {
-		err = rows.Scan(&q.SubscriptionID, &q.SlackUserName, &q.Channel, &q.Symbol, &q.TargetPrice, &q.Direction, &q.CurrentPrice)
+		err = rows.Scan(&q.SubscriptionID, &q.TeamID, &q.SlackUserName, &q.Channel, &q.Symbol, &q.TargetPrice, &q.Direction, &q.Currency, &q.CurrentPrice)
 		if err != nil {
 			panic(err)
 		}
 
+		if q.Currency != "" {
+			price, err := alertManager.stockBot.ConvertPrice(q.Symbol, q.CurrentPrice, q.Currency)
+			if err != nil {
+				logger.Warn("cannot check the alert", "id", q.SubscriptionID, "err", err)
+				continue
+			}
+			q.CurrentPrice = price
+			if !isBreached(q.Direction, q.CurrentPrice, q.TargetPrice) {
+				continue
+			}
+		}
+
 		if q.CurrentPrice != 0 {
 			notifications = append(notifications, q)
 		}
@@ -447,3 +849,15 @@ func (alertManager *AlertManager) GetPriceBreaches() []PriceBreachNotification {
 
 	return notifications
 }
+
+// isBreached - the price has reached the target in the direction of the alert
+func isBreached(direction string, price float64, targetPrice float64) bool {
+	switch direction {
+	case "ABOVE":
+		return price >= targetPrice
+	case "BELOW":
+		return price <= targetPrice
+	default:
+		return false
+	}
+}
@@ -1,29 +1,54 @@
 package alerts
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
 	"net/http"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
 	_ "github.com/lib/pq"
+	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
+	"github.com/magmasystems/SlackStockSlashCommand/framework/logging"
+	"github.com/magmasystems/SlackStockSlashCommand/preferences"
+	"github.com/magmasystems/SlackStockSlashCommand/slackmessaging"
 	"github.com/magmasystems/SlackStockSlashCommand/stockbot"
 	"github.com/nlopes/slack"
 )
 
 func TestCreateAlertManager(t *testing.T) {
 	type args struct {
-		bot *stockbot.Stockbot
-	}
+		appSettings *config.AppSettings
+		db          *sql.DB
+		bot         stockbot.BotOps
+		prefs       preferences.PreferenceStoreOps
+		notify      slackmessaging.NotifyFunc
+	}
+	appSettings := &config.AppSettings{Driver: "quandl", APIKeys: map[string]string{"quandl": "demo"}}
+	db := sql.OpenDB(&fakeAlertDB{})
+	defer db.Close()
+	bot := stockbot.CreateStockbot(appSettings, nil)
+	defer bot.Close()
+	prefs := preferences.CreatePreferenceStore(db)
+
 	tests := []struct {
 		name string
 		args args
 		want *AlertManager
 	}{
-		// TODO: Add test cases.
+		{"without preferences", args{appSettings, db, bot, nil, nil},
+			&AlertManager{db: &timedDB{db}, config: appSettings, stockBot: bot}},
+		{"with the preference store", args{appSettings, db, bot, prefs, nil},
+			&AlertManager{db: &timedDB{db}, config: appSettings, stockBot: bot, preferences: prefs}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := CreateAlertManager(tt.args.bot); !reflect.DeepEqual(got, tt.want) {
+			if got := CreateAlertManager(tt.args.appSettings, tt.args.db, tt.args.bot, tt.args.prefs, tt.args.notify); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("CreateAlertManager() = %v, want %v", got, tt.want)
 			}
 		})
@@ -48,6 +73,7 @@ func TestAlertManager_HandleQuoteAlert(t *testing.T) {
 	type args struct {
 		slashCommand slack.SlashCommand
 		writer       http.ResponseWriter
+		log          *logging.Logger
 	}
 	tests := []struct {
 		name         string
@@ -58,26 +84,27 @@ func TestAlertManager_HandleQuoteAlert(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tt.alertManager.HandleQuoteAlert(tt.args.slashCommand, tt.args.writer)
+			tt.alertManager.HandleQuoteAlert(tt.args.slashCommand, tt.args.writer, tt.args.log)
 		})
 	}
 }
 
 func TestAlertManager_listAllAlerts(t *testing.T) {
 	type args struct {
+		teamID string
 		userID string
 	}
 	tests := []struct {
 		name         string
 		alertManager *AlertManager
 		args         args
-		want         string
+		want         slack.Message
 	}{
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.alertManager.listAllAlerts(tt.args.userID); got != tt.want {
+			if got := tt.alertManager.listAllAlerts(tt.args.teamID, tt.args.userID); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("AlertManager.listAllAlerts() = %v, want %v", got, tt.want)
 			}
 		})
@@ -86,6 +113,7 @@ func TestAlertManager_listAllAlerts(t *testing.T) {
 
 func TestAlertManager_getAlert(t *testing.T) {
 	type args struct {
+		teamID string
 		userID string
 		params *createAlertParams
 	}
@@ -99,7 +127,7 @@ func TestAlertManager_getAlert(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.alertManager.getAlert(tt.args.userID, tt.args.params); !reflect.DeepEqual(got, tt.want) {
+			if got := tt.alertManager.getAlert(tt.args.teamID, tt.args.userID, tt.args.params); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("AlertManager.getAlert() = %v, want %v", got, tt.want)
 			}
 		})
@@ -108,6 +136,7 @@ func TestAlertManager_getAlert(t *testing.T) {
 
 func TestAlertManager_insertNewAlert(t *testing.T) {
 	type args struct {
+		teamID string
 		userID string
 		params *createAlertParams
 	}
@@ -122,7 +151,7 @@ func TestAlertManager_insertNewAlert(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := tt.alertManager.insertNewAlert(tt.args.userID, tt.args.params)
+			got, err := tt.alertManager.insertNewAlert(tt.args.teamID, tt.args.userID, tt.args.params)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("AlertManager.insertNewAlert() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -154,6 +183,7 @@ func TestAlertManager_setWasNotified(t *testing.T) {
 
 func TestAlertManager_deleteAllAlerts(t *testing.T) {
 	type args struct {
+		teamID string
 		userID string
 	}
 	tests := []struct {
@@ -165,13 +195,14 @@ func TestAlertManager_deleteAllAlerts(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tt.alertManager.deleteAllAlerts(tt.args.userID)
+			tt.alertManager.deleteAllAlerts(tt.args.teamID, tt.args.userID)
 		})
 	}
 }
 
 func TestAlertManager_deleteAlert(t *testing.T) {
 	type args struct {
+		teamID string
 		userID string
 		params *createAlertParams
 	}
@@ -185,7 +216,7 @@ func TestAlertManager_deleteAlert(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.alertManager.deleteAlert(tt.args.userID, tt.args.params); got != tt.want {
+			if got := tt.alertManager.deleteAlert(tt.args.teamID, tt.args.userID, tt.args.params); got != tt.want {
 				t.Errorf("AlertManager.deleteAlert() = %v, want %v", got, tt.want)
 			}
 		})
@@ -249,6 +280,57 @@ func TestAlertManager_GetAlertedSymbols(t *testing.T) {
 	}
 }
 
+func TestAlertManager_GetAlertedSymbolsOf(t *testing.T) {
+	type args struct {
+		teamID string
+		userID string
+	}
+	// U2 set an alert on IBM before OAuth, so it has no team
+	db := sql.OpenDB(&fakeAlertDB{rows: []fakeAlertRow{
+		{teamID: "T1", userID: "U1", symbol: "MSFT"},
+		{teamID: "", userID: "U2", symbol: "IBM"},
+		{teamID: "T1", userID: "U2", symbol: "AAPL"},
+	}})
+	defer db.Close()
+	alertManager := &AlertManager{db: &timedDB{db}}
+
+	tests := []struct {
+		name         string
+		alertManager *AlertManager
+		args         args
+		want         []string
+	}{
+		{"only the alerts of the user", alertManager, args{"T1", "U1"}, []string{"MSFT"}},
+		{"the alerts from before OAuth are still reachable", alertManager, args{"T1", "U2"}, []string{"AAPL", "IBM"}},
+		{"once they have been claimed", alertManager, args{"T1", "U2"}, []string{"AAPL", "IBM"}},
+		{"by the team of the user only", alertManager, args{"T2", "U2"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.alertManager.GetAlertedSymbolsOf(tt.args.teamID, tt.args.userID); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AlertManager.GetAlertedSymbolsOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertManager_GetAlertedSymbolsByUser(t *testing.T) {
+	tests := []struct {
+		name         string
+		alertManager *AlertManager
+		want         []UserSymbols
+	}{
+		// TODO: Add test cases.
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.alertManager.GetAlertedSymbolsByUser(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AlertManager.GetAlertedSymbolsByUser() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestAlertManager_SavePrices(t *testing.T) {
 	type args struct {
 		prices []PriceInfo
@@ -286,3 +368,126 @@ func TestAlertManager_GetPriceBreaches(t *testing.T) {
 		})
 	}
 }
+
+func Test_isBreached(t *testing.T) {
+	tests := []struct {
+		name        string
+		direction   string
+		price       float64
+		targetPrice float64
+		want        bool
+	}{
+		{"above and over", "ABOVE", 131, 130, true},
+		{"above and at", "ABOVE", 130, 130, true},
+		{"above and under", "ABOVE", 129, 130, false},
+		{"below and under", "BELOW", 129, 130, true},
+		{"below and over", "BELOW", 131, 130, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBreached(tt.direction, tt.price, tt.targetPrice); got != tt.want {
+				t.Errorf("isBreached() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeAlertDB - an alertsubscription table in memory, which understands just enough SQL to claim
+// the alerts from before OAuth and to get the alerted symbols of a user
+type fakeAlertDB struct {
+	rows []fakeAlertRow
+}
+
+type fakeAlertRow struct {
+	teamID string
+	userID string
+	symbol string
+}
+
+func (db *fakeAlertDB) Connect(context.Context) (driver.Conn, error) { return db, nil }
+func (db *fakeAlertDB) Driver() driver.Driver                        { return nil }
+func (db *fakeAlertDB) Prepare(query string) (driver.Stmt, error) {
+	return &fakeAlertStmt{db, query}, nil
+}
+func (db *fakeAlertDB) Close() error              { return nil }
+func (db *fakeAlertDB) Begin() (driver.Tx, error) { return nil, errors.New("no transactions") }
+
+type fakeAlertStmt struct {
+	db    *fakeAlertDB
+	query string
+}
+
+func (stmt *fakeAlertStmt) Close() error  { return nil }
+func (stmt *fakeAlertStmt) NumInput() int { return -1 }
+
+func (stmt *fakeAlertStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if !strings.Contains(stmt.query, "SET teamid = $1 WHERE teamid = ''") {
+		return nil, errors.New("unexpected statement: " + stmt.query)
+	}
+	var n int64
+	for i, row := range stmt.db.rows {
+		if row.teamID == "" && row.userID == args[1] {
+			stmt.db.rows[i].teamID = args[0].(string)
+			n++
+		}
+	}
+	return driver.RowsAffected(n), nil
+}
+
+func (stmt *fakeAlertStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if !strings.HasPrefix(stmt.query, "SELECT DISTINCT symbol") {
+		return nil, errors.New("unexpected query: " + stmt.query)
+	}
+	var symbols []string
+	for _, row := range stmt.db.rows {
+		if row.teamID == args[0] && row.userID == args[1] {
+			symbols = append(symbols, row.symbol)
+		}
+	}
+	sort.Strings(symbols)
+	return &fakeSymbolRows{symbols}, nil
+}
+
+type fakeSymbolRows struct {
+	symbols []string
+}
+
+func (rows *fakeSymbolRows) Columns() []string { return []string{"symbol"} }
+func (rows *fakeSymbolRows) Close() error      { return nil }
+
+func (rows *fakeSymbolRows) Next(dest []driver.Value) error {
+	if len(rows.symbols) == 0 {
+		return io.EOF
+	}
+	dest[0], rows.symbols = rows.symbols[0], rows.symbols[1:]
+	return nil
+}
+
+func Test_limitAlertList(t *testing.T) {
+	tests := []struct {
+		name       string
+		alerts     int
+		wantItems  int
+		wantFooter string
+	}{
+		{"a few", 3, 3, ""},
+		{"as many as fit", maxListedAlerts, maxListedAlerts, ""},
+		{"too many", 60, maxListedAlerts, "...and 36 more"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format := slackmessaging.SlackMessageFormat{Title: "Your Price Alerts"}
+			for i := 0; i < tt.alerts; i++ {
+				format.Items = append(format.Items, alertListItem(&quoteAlert{id: i, symbol: "MSFT", price: 130, direction: "BELOW"}, preferences.Default()))
+			}
+
+			limitAlertList(&format)
+			if len(format.Items) != tt.wantItems || format.Footer != tt.wantFooter {
+				t.Errorf("limitAlertList() left %d alerts and the footer %q, want %d and %q", len(format.Items), format.Footer, tt.wantItems, tt.wantFooter)
+			}
+			if blocks := len(format.ToBlock().Blocks.BlockSet); blocks > 50 {
+				t.Errorf("the alert list has %d blocks, and Slack allows 50", blocks)
+			}
+		})
+	}
+}
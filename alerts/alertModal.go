@@ -0,0 +1,214 @@
+package alerts
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/magmasystems/SlackStockSlashCommand/slackmessaging"
+	"github.com/magmasystems/SlackStockSlashCommand/stockbot"
+)
+
+// The callback id of the modal that creates and edits alerts
+const alertModalCallbackID = "alert_modal"
+
+// Slack gives up on a view submission that is not answered within 3 seconds. A symbol lookup that takes longer
+// than this finishes after the modal has closed, and the user gets the outcome as a DM.
+const viewSubmissionTimeout = 2 * time.Second
+
+// The names of the inputs on the alert modal
+const (
+	modalSymbol    = "symbol"
+	modalPrice     = "price"
+	modalDirection = "direction"
+	modalChannel   = "channel"
+	modalExpiry    = "expiry"
+	modalCurrency  = "currency"
+)
+
+var (
+	validSymbol   = regexp.MustCompile(`^[A-Za-z0-9.\-^=/]{1,12}$`)
+	validCurrency = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// createAlertModal - builds the modal. If an existing alert is passed in, the modal edits that alert.
+// Its expiry date is shown in the location, which should be the user's time zone.
+func createAlertModal(existing *quoteAlert, location *time.Location) slackmessaging.SlackModal {
+	modal := slackmessaging.SlackModal{
+		CallbackID: alertModalCallbackID,
+		Title:      "New Price Alert",
+		SubmitText: "Create",
+		Inputs: []slackmessaging.SlackModalInput{
+			{Name: modalSymbol, Label: "Symbol", Placeholder: "MSFT"},
+			{Name: modalPrice, Label: "Target price", Placeholder: "130.00"},
+			{Name: modalDirection, Label: "Alert me when the price goes", Kind: slackmessaging.ModalInputSelect, Options: []string{"ABOVE", "BELOW"}, InitialValue: "ABOVE"},
+			{Name: modalChannel, Label: "Post the alert to (leave empty for a DM)", Kind: slackmessaging.ModalInputChannel, Optional: true},
+			{Name: modalExpiry, Label: "Stop watching after", Kind: slackmessaging.ModalInputDate, Optional: true},
+			{Name: modalCurrency, Label: "Currency of the target price (leave empty for the symbol's own)", Placeholder: "EUR", Optional: true},
+		},
+	}
+
+	if existing != nil {
+		modal.Title = "Edit Price Alert"
+		modal.SubmitText = "Save"
+		modal.PrivateMetadata = strconv.Itoa(existing.id)
+		modal.Inputs[0].InitialValue = existing.symbol
+		modal.Inputs[1].InitialValue = strconv.FormatFloat(existing.price, 'f', -1, 64)
+		modal.Inputs[2].InitialValue = existing.direction
+		if !strings.HasPrefix(existing.channel, "#") {
+			modal.Inputs[3].InitialValue = existing.channel // channels that were picked in the modal are stored by id
+		}
+		if existing.expiresAt.Valid {
+			modal.Inputs[4].InitialValue = lastDay(existing.expiresAt.Time, location).Format("2006-01-02")
+		}
+		modal.Inputs[5].InitialValue = existing.currency
+	}
+
+	return modal
+}
+
+// validateAlertModal - checks the submitted modal and turns it into the alert params.
+// The errors are keyed by the name of the input that they belong to.
+// The expiry date is read in the time zone of now, which should be the user's.
+func validateAlertModal(view *slackmessaging.ViewSubmission, now time.Time) (*createAlertParams, map[string]string) {
+	errors := make(map[string]string)
+	params := &createAlertParams{direction: "ABOVE"}
+
+	params.symbol = strings.ToUpper(strings.TrimSpace(view.Value(modalSymbol)))
+	if !validSymbol.MatchString(params.symbol) {
+		errors[modalSymbol] = "Enter a ticker symbol, like MSFT or BRK.B, or a pair, like EURUSD or BTC-USD"
+	} else {
+		params.symbol = stockbot.NormalizeSymbol(params.symbol).Symbol
+	}
+
+	price, err := strconv.ParseFloat(strings.TrimPrefix(strings.TrimSpace(view.Value(modalPrice)), "$"), 64)
+	if err != nil || price <= 0 {
+		errors[modalPrice] = "Enter a price greater than zero, like 130.50"
+	}
+	params.price = price
+
+	switch direction := strings.ToUpper(view.Value(modalDirection)); direction {
+	case "ABOVE", "BELOW":
+		params.direction = direction
+	case "":
+	default:
+		errors[modalDirection] = "Choose ABOVE or BELOW"
+	}
+
+	params.channel = view.Value(modalChannel)
+
+	params.currency = strings.ToUpper(strings.TrimSpace(view.Value(modalCurrency)))
+	if params.currency != "" && !validCurrency.MatchString(params.currency) {
+		errors[modalCurrency] = "Enter a currency code, like USD or EUR"
+	}
+
+	if expiry := view.Value(modalExpiry); expiry != "" {
+		date, err := time.ParseInLocation("2006-01-02", expiry, now.Location())
+		if err != nil {
+			errors[modalExpiry] = "Choose a date"
+		} else if date = date.AddDate(0, 0, 1); !date.After(now) {
+			errors[modalExpiry] = "The date must not be in the past"
+		} else {
+			// The alert is good until the end of the chosen day
+			params.expiresAt = pq.NullTime{Time: date, Valid: true}
+		}
+	}
+
+	if len(errors) > 0 {
+		return nil, errors
+	}
+	return params, nil
+}
+
+// openAlertModal - opens the modal that creates a new alert, or edits an existing one of the user
+func (alertManager *AlertManager) openAlertModal(teamID string, userID string, triggerID string, existing *quoteAlert) error {
+	location := alertManager.preferencesOf(teamID, userID).Location()
+	return slackmessaging.OpenModal(teamID, triggerID, createAlertModal(existing, location))
+}
+
+// lastDay - the day that the alert is good until the end of. An alert is stored as expiring at the midnight after it.
+func lastDay(expiresAt time.Time, location *time.Location) time.Time {
+	return expiresAt.In(location).AddDate(0, 0, -1)
+}
+
+// HandleViewSubmission - creates or updates the alert when the user submits the alert modal
+func (alertManager *AlertManager) HandleViewSubmission(interaction slackmessaging.Interaction, writer http.ResponseWriter) {
+	if interaction.View == nil || interaction.View.CallbackID != alertModalCallbackID {
+		writer.WriteHeader(http.StatusOK)
+		return
+	}
+
+	teamID := interaction.Team.ID
+	userID := interaction.User.ID
+	alertManager.claimAlertsFromBeforeOAuth(teamID, userID)
+	now := time.Now().In(alertManager.preferencesOf(teamID, userID).Location())
+	params, errors := validateAlertModal(interaction.View, now)
+	if errors != nil {
+		slackmessaging.WriteViewErrors(writer, errors)
+		return
+	}
+	logger.Debug("got the alert modal", "params", fmt.Sprint(params))
+
+	// The symbol and the exchange rate are looked up in the background, so that a slow provider cannot make Slack give up
+	outcome := make(chan alertModalOutcome, 1)
+	go func() {
+		// The database calls panic when they fail. Out here, nothing else would recover from that.
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("cannot save the alert from the modal", "symbol", params.symbol, "err", r)
+				outcome <- alertModalOutcome{errors: map[string]string{modalSymbol: "Your alert cannot be saved right now"}}
+			}
+		}()
+		outcome <- alertManager.saveAlertModal(teamID, userID, interaction.View.PrivateMetadata, params)
+	}()
+
+	select {
+	case result := <-outcome:
+		if result.errors != nil {
+			slackmessaging.WriteViewErrors(writer, result.errors)
+			return
+		}
+		// An empty response closes the modal. The confirmation goes to the user as a DM.
+		writer.WriteHeader(http.StatusOK)
+		go alertManager.notify(teamID, userID, "", slackmessaging.SlackMessageFormat{Color: "good", Text: result.text, UseTime: true})
+
+	case <-time.After(viewSubmissionTimeout):
+		logger.Info("the alert modal is taking too long, so it is answered by DM", "symbol", params.symbol)
+		writer.WriteHeader(http.StatusOK)
+		go func() {
+			result := <-outcome
+			format := slackmessaging.SlackMessageFormat{Color: "good", Text: result.text, UseTime: true}
+			for _, problem := range result.errors {
+				format.Color, format.Text = "danger", fmt.Sprintf("Your alert on %s was not saved. %s", params.symbol, problem)
+			}
+			alertManager.notify(teamID, userID, "", format)
+		}()
+	}
+}
+
+// alertModalOutcome - the confirmation of an alert that was saved from the modal, or the problems that stopped it from being saved
+type alertModalOutcome struct {
+	text   string
+	errors map[string]string
+}
+
+// saveAlertModal - checks the alert just like /quote-alert does, and then creates it, or updates the alert whose id is in the metadata
+func (alertManager *AlertManager) saveAlertModal(teamID string, userID string, metadata string, params *createAlertParams) alertModalOutcome {
+	if field, err := alertManager.checkAlert(params); err != nil {
+		return alertModalOutcome{errors: map[string]string{field: err.Error()}}
+	}
+
+	if id, err := strconv.Atoi(metadata); err == nil {
+		if !alertManager.updateAlert(teamID, userID, id, params) {
+			return alertModalOutcome{errors: map[string]string{modalSymbol: "This alert no longer exists"}}
+		}
+		return alertModalOutcome{text: fmt.Sprintf("Alert %d on %s updated", id, params.symbol)}
+	}
+
+	newID := alertManager.saveAlert(teamID, userID, params)
+	return alertModalOutcome{text: fmt.Sprintf("Alert %s on %s created", newID, params.symbol)}
+}
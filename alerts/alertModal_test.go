@@ -0,0 +1,191 @@
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/magmasystems/SlackStockSlashCommand/preferences"
+	"github.com/magmasystems/SlackStockSlashCommand/slackmessaging"
+)
+
+func TestValidateAlertModal(t *testing.T) {
+	now := time.Date(2019, 6, 24, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		state      string
+		wantErrors []string
+		wantSymbol string
+		wantPrice  float64
+		wantExpiry bool
+	}{
+		{
+			"valid alert",
+			`{"symbol":{"symbol":{"type":"plain_text_input","value":" brk.b "}},
+			  "price":{"price":{"type":"plain_text_input","value":"$215.50"}},
+			  "direction":{"direction":{"type":"static_select","selected_option":{"value":"BELOW"}}},
+			  "channel":{"channel":{"type":"channels_select","selected_channel":"C0123"}},
+			  "expiry":{"expiry":{"type":"datepicker","selected_date":"2019-06-24"}}}`,
+			nil, "BRK.B", 215.50, true,
+		},
+		{
+			"missing fields",
+			`{"symbol":{"symbol":{"type":"plain_text_input","value":""}},
+			  "price":{"price":{"type":"plain_text_input","value":"abc"}}}`,
+			[]string{modalSymbol, modalPrice}, "", 0, false,
+		},
+		{
+			"negative price and past expiry",
+			`{"symbol":{"symbol":{"type":"plain_text_input","value":"MSFT"}},
+			  "price":{"price":{"type":"plain_text_input","value":"-3"}},
+			  "expiry":{"expiry":{"type":"datepicker","selected_date":"2019-06-23"}}}`,
+			[]string{modalPrice, modalExpiry}, "", 0, false,
+		},
+		{
+			"bad currency",
+			`{"symbol":{"symbol":{"type":"plain_text_input","value":"SAP.DE"}},
+			  "price":{"price":{"type":"plain_text_input","value":"120"}},
+			  "currency":{"currency":{"type":"plain_text_input","value":"euros"}}}`,
+			[]string{modalCurrency}, "", 0, false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := new(slackmessaging.ViewSubmission)
+			if err := json.Unmarshal([]byte(`{"state":{"values":`+tt.state+`}}`), view); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+
+			params, errors := validateAlertModal(view, now)
+			if len(errors) != len(tt.wantErrors) {
+				t.Fatalf("validateAlertModal() errors = %v, want errors on %v", errors, tt.wantErrors)
+			}
+			for _, name := range tt.wantErrors {
+				if _, ok := errors[name]; !ok {
+					t.Errorf("validateAlertModal() has no error on %s", name)
+				}
+			}
+			if tt.wantErrors != nil {
+				return
+			}
+
+			if params.symbol != tt.wantSymbol || params.price != tt.wantPrice || params.expiresAt.Valid != tt.wantExpiry {
+				t.Errorf("validateAlertModal() = %+v", params)
+			}
+		})
+	}
+}
+
+func TestValidateAlertModal_ExpiryInUserTimeZone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("the time zone database is not installed")
+	}
+	// It is already the 25th in Tokyo
+	now := time.Date(2019, 6, 24, 20, 0, 0, 0, time.UTC).In(tokyo)
+	state := func(date string) string {
+		return `{"symbol":{"symbol":{"type":"plain_text_input","value":"MSFT"}},
+		  "price":{"price":{"type":"plain_text_input","value":"130"}},
+		  "expiry":{"expiry":{"type":"datepicker","selected_date":"` + date + `"}}}`
+	}
+
+	tests := []struct {
+		name       string
+		date       string
+		wantErrors bool
+		wantExpiry time.Time
+	}{
+		{"yesterday in Tokyo", "2019-06-24", true, time.Time{}},
+		{"today in Tokyo lasts until its midnight", "2019-06-25", false, time.Date(2019, 6, 26, 0, 0, 0, 0, tokyo)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := new(slackmessaging.ViewSubmission)
+			if err := json.Unmarshal([]byte(`{"state":{"values":`+state(tt.date)+`}}`), view); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+
+			params, errors := validateAlertModal(view, now)
+			if (errors != nil) != tt.wantErrors {
+				t.Fatalf("validateAlertModal() errors = %v, want errors %v", errors, tt.wantErrors)
+			}
+			if !tt.wantErrors && !params.expiresAt.Time.Equal(tt.wantExpiry) {
+				t.Errorf("validateAlertModal() expires at %v, want %v", params.expiresAt.Time, tt.wantExpiry)
+			}
+		})
+	}
+}
+
+// submitUnchanged - the view that Slack sends back when the user saves the modal without changing anything
+func submitUnchanged(t *testing.T, modal slackmessaging.SlackModal) *slackmessaging.ViewSubmission {
+	values := make(map[string]string)
+	for _, input := range modal.Inputs {
+		values[input.Name] = input.InitialValue
+	}
+	state := fmt.Sprintf(`{"symbol":{"symbol":{"type":"plain_text_input","value":%q}},
+	  "price":{"price":{"type":"plain_text_input","value":%q}},
+	  "direction":{"direction":{"type":"static_select","selected_option":{"value":%q}}},
+	  "expiry":{"expiry":{"type":"datepicker","selected_date":%q}},
+	  "currency":{"currency":{"type":"plain_text_input","value":%q}}}`,
+		values[modalSymbol], values[modalPrice], values[modalDirection], values[modalExpiry], values[modalCurrency])
+
+	view := new(slackmessaging.ViewSubmission)
+	if err := json.Unmarshal([]byte(`{"state":{"values":`+state+`}}`), view); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	return view
+}
+
+func TestAlertModal_UnchangedEditKeepsTheAlert(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("the time zone database is not installed")
+	}
+	now := time.Date(2019, 6, 24, 20, 0, 0, 0, time.UTC).In(tokyo)
+	expiresAt := time.Date(2020, 1, 1, 0, 0, 0, 0, tokyo) // the end of 2019-12-31 in Tokyo
+
+	existing := &quoteAlert{id: 7, symbol: "MSFT", price: 130, direction: "BELOW", expiresAt: pq.NullTime{Time: expiresAt, Valid: true}}
+	modal := createAlertModal(existing, tokyo)
+	if got := modal.Inputs[4].InitialValue; got != "2019-12-31" {
+		t.Errorf("createAlertModal() expiry = %s, want 2019-12-31", got)
+	}
+
+	params, errors := validateAlertModal(submitUnchanged(t, modal), now)
+	if errors != nil {
+		t.Fatalf("validateAlertModal() errors = %v", errors)
+	}
+	if !params.expiresAt.Time.Equal(expiresAt) {
+		t.Errorf("saving the unchanged alert moved its expiry to %v, want %v", params.expiresAt.Time, expiresAt)
+	}
+	if params.price != existing.price || params.direction != existing.direction || params.symbol != existing.symbol {
+		t.Errorf("saving the unchanged alert changed it to %+v", params)
+	}
+}
+
+func TestAlertListItem_Expiry(t *testing.T) {
+	prefs := preferences.Preferences{Decimals: 2, TimeZone: "UTC"}
+	q := &quoteAlert{id: 7, symbol: "MSFT", price: 130, direction: "BELOW",
+		expiresAt: pq.NullTime{Time: time.Date(2999, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true}}
+
+	if got := alertListItem(q, prefs).Text; !strings.HasSuffix(got, "_until Dec 31_") {
+		t.Errorf("alertListItem() = %q, want it to be good until Dec 31", got)
+	}
+}
+
+func TestAlertModal_UnchangedEditKeepsThePrice(t *testing.T) {
+	now := time.Date(2019, 6, 24, 12, 0, 0, 0, time.UTC)
+	for _, price := range []float64{1.1234, 0.00042, 130, 2500.5} {
+		existing := &quoteAlert{id: 7, symbol: "MSFT", price: price, direction: "BELOW"}
+		params, errors := validateAlertModal(submitUnchanged(t, createAlertModal(existing, time.UTC)), now)
+		if errors != nil {
+			t.Fatalf("validateAlertModal() errors = %v", errors)
+		}
+		if params.price != price {
+			t.Errorf("saving the unchanged alert changed its price from %v to %v", price, params.price)
+		}
+	}
+}
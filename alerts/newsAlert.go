@@ -0,0 +1,301 @@
+package alerts
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	q "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+	"github.com/magmasystems/SlackStockSlashCommand/stockbot"
+)
+
+// A volume alert counts the articles of the last day, and fires at most once a day
+const newsVolumeWindow = 24 * time.Hour
+
+// The oldest news that is fetched for a keyword alert that has not been checked for a while
+const maxNewsLookback = 3 * 24 * time.Hour
+
+// newsAlert - an alert on the news of a symbol. A keyword alert fires for every new article that mentions
+// the keyword. A volume alert fires when at least minArticles articles were published in the last day.
+type newsAlert struct {
+	id             int
+	teamID         string
+	slackUserName  string
+	channel        string
+	symbol         string
+	keyword        string
+	minArticles    int
+	lastCheckedAt  time.Time
+	lastNotifiedAt pq.NullTime
+}
+
+// newsAlertParams - the parsed arguments of "/quote-news alert"
+type newsAlertParams struct {
+	symbol      string
+	keyword     string
+	minArticles int
+	channel     string
+}
+
+// NewsNotification - the articles that made a news alert fire
+type NewsNotification struct {
+	TeamID        string
+	SlackUserName string
+	Channel       string
+	Symbol        string
+	Reason        string // like `2 new articles about MSFT mention "antitrust"`
+	Items         []q.NewsItem
+}
+
+// parseNewsAlertCommand - parses "MSFT keyword=antitrust #channel" or "MSFT volume=10"
+func parseNewsAlertCommand(text string) (*newsAlertParams, error) {
+	tokens, err := tokenizeAlertCommand(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 || tokens[0].key != "" {
+		return nil, fmt.Errorf("expected a symbol, like MSFT keyword=antitrust")
+	}
+
+	symbol := strings.ToUpper(tokens[0].text)
+	if !validSymbol.MatchString(symbol) {
+		return nil, fmt.Errorf("%s is not a valid symbol", tokens[0].text)
+	}
+	instrument := stockbot.NormalizeSymbol(symbol)
+	if instrument.AssetClass == stockbot.AssetFX || instrument.AssetClass == stockbot.AssetCrypto {
+		return nil, fmt.Errorf("%s is a currency pair, and news is only available for stocks and ETFs", instrument.Symbol)
+	}
+	params := &newsAlertParams{symbol: instrument.Symbol}
+
+	for _, token := range tokens[1:] {
+		switch {
+		case token.key == "keyword":
+			if params.keyword != "" {
+				return nil, fmt.Errorf("the keyword is given more than once")
+			}
+			params.keyword = strings.TrimSpace(token.text)
+		case token.key == "volume":
+			if params.minArticles != 0 {
+				return nil, fmt.Errorf("the volume is given more than once")
+			}
+			volume, err := strconv.Atoi(token.text)
+			if err != nil || volume <= 0 {
+				return nil, fmt.Errorf("the volume must be a number of articles, not %s", token.text)
+			}
+			params.minArticles = volume
+		case token.key == "channel" || (token.key == "" && strings.HasPrefix(token.text, "#")):
+			if params.channel != "" {
+				return nil, fmt.Errorf("the channel is given more than once")
+			}
+			params.channel = "#" + strings.TrimPrefix(strings.TrimSpace(token.text), "#")
+			if params.channel == "#" {
+				return nil, fmt.Errorf("expected the name of a channel after the #")
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %s (expected keyword=word, volume=number or a #channel)", token)
+		}
+	}
+
+	if (params.keyword == "") == (params.minArticles == 0) {
+		return nil, fmt.Errorf("expected either keyword=word or volume=number")
+	}
+	return params, nil
+}
+
+// evaluate - the articles that make the alert fire, and why. The alert does not fire if the reason is empty.
+func (alert *newsAlert) evaluate(items []q.NewsItem, now time.Time) (string, []q.NewsItem) {
+	var matched []q.NewsItem
+
+	if alert.keyword != "" {
+		keyword := strings.ToLower(alert.keyword)
+		for _, item := range items {
+			if item.PublishedAt.After(alert.lastCheckedAt) &&
+				(strings.Contains(strings.ToLower(item.Title), keyword) || strings.Contains(strings.ToLower(item.Summary), keyword)) {
+				matched = append(matched, item)
+			}
+		}
+		if len(matched) == 0 {
+			return "", nil
+		}
+		if len(matched) == 1 {
+			return fmt.Sprintf("A new article about %s mentions \"%s\"", alert.symbol, alert.keyword), matched
+		}
+		return fmt.Sprintf("%d new articles about %s mention \"%s\"", len(matched), alert.symbol, alert.keyword), matched
+	}
+
+	if alert.lastNotifiedAt.Valid && now.Sub(alert.lastNotifiedAt.Time) < newsVolumeWindow {
+		return "", nil
+	}
+	for _, item := range items {
+		if now.Sub(item.PublishedAt) <= newsVolumeWindow {
+			matched = append(matched, item)
+		}
+	}
+	if len(matched) < alert.minArticles {
+		return "", nil
+	}
+	return fmt.Sprintf("%d articles about %s in the last day", len(matched), alert.symbol), matched
+}
+
+// CreateNewsAlert - creates a news alert from the text after "/quote-news alert"
+func (alertManager *AlertManager) CreateNewsAlert(teamID string, userID string, text string) (string, error) {
+	params, err := parseNewsAlertCommand(text)
+	if err != nil {
+		return "", err
+	}
+	if params.channel == "" {
+		params.channel = alertManager.preferencesOf(teamID, userID).DefaultChannel
+	}
+
+	sqlStatement := `
+INSERT INTO slackstockbot.newsalert (teamid, slackuser, channel, symbol, keyword, minarticles)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id`
+	id := 0
+	err = alertManager.db.QueryRow(sqlStatement, teamID, userID, params.channel, params.symbol, params.keyword, params.minArticles).Scan(&id)
+	if err != nil {
+		logger.Error("cannot create the news alert", "symbol", params.symbol, "err", err)
+		return "", fmt.Errorf("the news alert cannot be saved right now")
+	}
+
+	if params.keyword != "" {
+		return fmt.Sprintf("News alert %d created: you will hear about new articles on %s that mention \"%s\"", id, params.symbol, params.keyword), nil
+	}
+	return fmt.Sprintf("News alert %d created: you will hear when there are %d or more articles on %s in a day", id, params.minArticles, params.symbol), nil
+}
+
+// ListNewsAlerts - the news alerts of a user, one per line
+func (alertManager *AlertManager) ListNewsAlerts(teamID string, userID string) string {
+	alerts, err := alertManager.getNewsAlerts(`WHERE teamid = $1 AND slackuser = $2 ORDER BY symbol, id`, teamID, userID)
+	if err != nil {
+		return "Your news alerts cannot be retrieved right now"
+	}
+	if len(alerts) == 0 {
+		return "You have no news alerts. Type `/quote-news help` to see how to create one."
+	}
+
+	outputText := "Your news alerts:\n"
+	for _, alert := range alerts {
+		outputText += fmt.Sprintf("%d: *%s* ", alert.id, alert.symbol)
+		if alert.keyword != "" {
+			outputText += fmt.Sprintf("articles that mention \"%s\"", alert.keyword)
+		} else {
+			outputText += fmt.Sprintf("%d or more articles in a day", alert.minArticles)
+		}
+		if alert.channel != "" {
+			outputText += " to " + alert.channel
+		}
+		outputText += "\n"
+	}
+	return outputText
+}
+
+// DeleteNewsAlert - removes one of the user's news alerts by its id
+func (alertManager *AlertManager) DeleteNewsAlert(teamID string, userID string, idText string) string {
+	id, err := strconv.Atoi(strings.TrimSpace(idText))
+	if err != nil {
+		return fmt.Sprintf("%s is not the id of a news alert. Type `/quote-news alerts` to see the ids.", idText)
+	}
+
+	// The team and user ids are part of the key so that a user can only delete their own alerts
+	res, err := alertManager.db.Exec(`DELETE FROM slackstockbot.newsalert WHERE teamid = $1 AND slackuser = $2 AND id = $3`, teamID, userID, id)
+	if err != nil {
+		logger.Error("cannot delete the news alert", "id", id, "err", err)
+		return "The news alert cannot be deleted right now"
+	}
+	if deleted, _ := res.RowsAffected(); deleted == 0 {
+		return fmt.Sprintf("You have no news alert %d", id)
+	}
+	return fmt.Sprintf("News alert %d deleted", id)
+}
+
+// CheckForNewsAlerts - fetches the news of every symbol that has news alerts, and calls the callback for each alert that fires
+func (alertManager *AlertManager) CheckForNewsAlerts(stockbot *stockbot.Stockbot, now time.Time, callback func(NewsNotification)) {
+	alerts, err := alertManager.getNewsAlerts(`ORDER BY symbol, id`)
+	if err != nil || len(alerts) == 0 {
+		return
+	}
+
+	bySymbol := make(map[string][]*newsAlert)
+	var symbols []string
+	for _, alert := range alerts {
+		if _, ok := bySymbol[alert.symbol]; !ok {
+			symbols = append(symbols, alert.symbol)
+		}
+		bySymbol[alert.symbol] = append(bySymbol[alert.symbol], alert)
+	}
+
+	for _, symbol := range symbols {
+		// Enough news for the volume alerts, and everything since the keyword alerts were last checked
+		since := now.Add(-newsVolumeWindow)
+		for _, alert := range bySymbol[symbol] {
+			if alert.lastCheckedAt.Before(since) {
+				since = alert.lastCheckedAt
+			}
+		}
+		if oldest := now.Add(-maxNewsLookback); since.Before(oldest) {
+			since = oldest
+		}
+
+		items, err := stockbot.FetchNews(symbol, since)
+		if err != nil {
+			logger.Warn("cannot get the news", "symbol", symbol, "err", err)
+			continue
+		}
+
+		for _, alert := range bySymbol[symbol] {
+			if reason, matched := alert.evaluate(items, now); reason != "" {
+				callback(NewsNotification{
+					TeamID:        alert.teamID,
+					SlackUserName: alert.slackUserName,
+					Channel:       alert.channel,
+					Symbol:        alert.symbol,
+					Reason:        reason,
+					Items:         matched,
+				})
+				alertManager.setNewsAlertChecked(alert.id, now, true)
+			} else {
+				alertManager.setNewsAlertChecked(alert.id, now, false)
+			}
+		}
+	}
+}
+
+// getNewsAlerts - the news alerts that match the WHERE and ORDER BY clauses
+func (alertManager *AlertManager) getNewsAlerts(clauses string, args ...interface{}) ([]*newsAlert, error) {
+	sqlStatement := `SELECT id, teamid, slackuser, channel, symbol, keyword, minarticles, lastcheckedat, lastnotifiedat
+	FROM slackstockbot.newsalert ` + clauses
+
+	rows, err := alertManager.db.Query(sqlStatement, args...)
+	if err != nil {
+		logger.Error("cannot get the news alerts", "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []*newsAlert
+	for rows.Next() {
+		alert := new(newsAlert)
+		err = rows.Scan(&alert.id, &alert.teamID, &alert.slackUserName, &alert.channel, &alert.symbol, &alert.keyword,
+			&alert.minArticles, &alert.lastCheckedAt, &alert.lastNotifiedAt)
+		if err != nil {
+			logger.Error("cannot read a news alert", "err", err)
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, rows.Err()
+}
+
+// setNewsAlertChecked - remembers when the alert was checked, so that a keyword alert only fires for newer articles
+func (alertManager *AlertManager) setNewsAlertChecked(id int, at time.Time, notified bool) {
+	sqlStatement := `UPDATE slackstockbot.newsalert SET lastcheckedat = $1 WHERE id = $2`
+	if notified {
+		sqlStatement = `UPDATE slackstockbot.newsalert SET lastcheckedat = $1, lastnotifiedat = $1 WHERE id = $2`
+	}
+	if _, err := alertManager.db.Exec(sqlStatement, at, id); err != nil {
+		logger.Error("cannot update the news alert", "id", id, "err", err)
+	}
+}
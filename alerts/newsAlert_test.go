@@ -0,0 +1,104 @@
+package alerts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	q "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+func TestParseNewsAlertCommand(t *testing.T) {
+	tests := []struct {
+		name            string
+		text            string
+		wantSymbol      string
+		wantKeyword     string
+		wantMinArticles int
+		wantChannel     string
+		wantErr         string
+	}{
+		{name: "keyword", text: "msft keyword=antitrust", wantSymbol: "MSFT", wantKeyword: "antitrust"},
+		{name: "quoted keyword with a channel", text: `MSFT keyword="cloud revenue" #news`, wantSymbol: "MSFT", wantKeyword: "cloud revenue", wantChannel: "#news"},
+		{name: "volume", text: "AAPL volume=10 channel=news", wantSymbol: "AAPL", wantMinArticles: 10, wantChannel: "#news"},
+
+		{name: "no symbol", text: "", wantErr: "expected a symbol, like MSFT keyword=antitrust"},
+		{name: "neither", text: "MSFT", wantErr: "expected either keyword=word or volume=number"},
+		{name: "both", text: "MSFT keyword=x volume=2", wantErr: "expected either keyword=word or volume=number"},
+		{name: "bad volume", text: "MSFT volume=lots", wantErr: "the volume must be a number of articles, not lots"},
+		{name: "currency pair", text: "EURUSD volume=5", wantErr: "EURUSD is a currency pair, and news is only available for stocks and ETFs"},
+		{name: "unexpected word", text: "MSFT keyword=x soon", wantErr: "unexpected soon (expected keyword=word, volume=number or a #channel)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNewsAlertCommand(tt.text)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("parseNewsAlertCommand() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseNewsAlertCommand() error = %v", err)
+			}
+			if got.symbol != tt.wantSymbol || got.keyword != tt.wantKeyword || got.minArticles != tt.wantMinArticles || got.channel != tt.wantChannel {
+				t.Errorf("parseNewsAlertCommand() = %+v", got)
+			}
+		})
+	}
+}
+
+func TestNewsAlert_evaluate(t *testing.T) {
+	now := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	items := []q.NewsItem{
+		{Title: "Microsoft faces antitrust probe", PublishedAt: now.Add(-1 * time.Hour)},
+		{Title: "Cloud growth", Summary: "Analysts shrug off ANTITRUST worries", PublishedAt: now.Add(-3 * time.Hour)},
+		{Title: "Old antitrust news", PublishedAt: now.Add(-30 * time.Hour)},
+	}
+
+	tests := []struct {
+		name       string
+		alert      newsAlert
+		wantReason string
+		wantItems  int
+	}{
+		{
+			"keyword matches the new articles only",
+			newsAlert{symbol: "MSFT", keyword: "antitrust", lastCheckedAt: now.Add(-2 * time.Hour)},
+			`A new article about MSFT mentions "antitrust"`, 1,
+		},
+		{
+			"keyword in the summary",
+			newsAlert{symbol: "MSFT", keyword: "antitrust", lastCheckedAt: now.Add(-4 * time.Hour)},
+			`2 new articles about MSFT mention "antitrust"`, 2,
+		},
+		{
+			"keyword that is not mentioned",
+			newsAlert{symbol: "MSFT", keyword: "dividend", lastCheckedAt: now.Add(-48 * time.Hour)},
+			"", 0,
+		},
+		{
+			"volume reached",
+			newsAlert{symbol: "MSFT", minArticles: 2, lastCheckedAt: now.Add(-time.Hour)},
+			"2 articles about MSFT in the last day", 2,
+		},
+		{
+			"volume not reached",
+			newsAlert{symbol: "MSFT", minArticles: 3, lastCheckedAt: now.Add(-time.Hour)},
+			"", 0,
+		},
+		{
+			"volume already notified today",
+			newsAlert{symbol: "MSFT", minArticles: 2, lastNotifiedAt: pq.NullTime{Time: now.Add(-5 * time.Hour), Valid: true}},
+			"", 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, matched := tt.alert.evaluate(items, now)
+			if reason != tt.wantReason || len(matched) != tt.wantItems {
+				t.Errorf("newsAlert.evaluate() = %q with %d items, want %q with %d", reason, len(matched), tt.wantReason, tt.wantItems)
+			}
+		})
+	}
+}
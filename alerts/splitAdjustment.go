@@ -0,0 +1,99 @@
+package alerts
+
+import (
+	"time"
+
+	q "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+	"github.com/magmasystems/SlackStockSlashCommand/stockbot"
+)
+
+// How far back the splits are looked for. Splits that were applied already are remembered, so the same split
+// is never applied twice, and the bot can be down for this long without missing one.
+const splitLookback = 7 * 24 * time.Hour
+
+// SplitAdjustment - an alert whose target price was moved because its stock split
+type SplitAdjustment struct {
+	AlertID       int
+	TeamID        string
+	SlackUserName string
+	Split         q.Split
+	OldPrice      float64
+	NewPrice      float64
+	Currency      string
+}
+
+// AdjustForSplits - looks for the recent splits of the alerted symbols, and moves the target price of every
+// alert that was set before the split, so that a 130 target on a 4-for-1 split becomes 32.50.
+// The callback is called for each alert that was moved.
+func (alertManager *AlertManager) AdjustForSplits(stockbot *stockbot.Stockbot, now time.Time, callback func(SplitAdjustment)) {
+	since := now.Add(-splitLookback)
+
+	for _, symbol := range alertManager.GetAlertedSymbols() {
+		splits, err := stockbot.FetchSplits(symbol, since)
+		if err != nil {
+			logger.Warn("cannot get the splits", "symbol", symbol, "err", err)
+			continue
+		}
+
+		for _, split := range splits {
+			adjustments, err := alertManager.applySplit(split)
+			if err != nil {
+				logger.Error("cannot apply the split", "symbol", symbol, "date", split.Date.Format("2006-01-02"), "err", err)
+				continue
+			}
+			for _, adjustment := range adjustments {
+				callback(adjustment)
+			}
+		}
+	}
+}
+
+// applySplit - divides the target prices of the symbol's alerts by the split ratio, unless the split was applied before.
+// Alerts whose target was set on or after the day of the split are already in post-split prices.
+func (alertManager *AlertManager) applySplit(split q.Split) ([]SplitAdjustment, error) {
+	tx, err := alertManager.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT INTO slackstockbot.splitadjustment (symbol, splitdate, ratio) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`,
+		split.Symbol, split.Date, split.Ratio)
+	if err != nil {
+		return nil, err
+	}
+	if inserted, _ := res.RowsAffected(); inserted == 0 {
+		return nil, nil
+	}
+
+	sqlStatement := `UPDATE slackstockbot.alertsubscription
+	SET targetprice = targetprice / $1, targetsetat = now()
+	WHERE symbol = $2 AND targetsetat < $3
+	RETURNING id, teamid, slackuser, targetprice, currency`
+
+	rows, err := tx.Query(sqlStatement, split.Ratio, split.Symbol, split.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	var adjustments []SplitAdjustment
+	for rows.Next() {
+		adjustment := SplitAdjustment{Split: split}
+		if err = rows.Scan(&adjustment.AlertID, &adjustment.TeamID, &adjustment.SlackUserName, &adjustment.NewPrice, &adjustment.Currency); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		adjustment.OldPrice = adjustment.NewPrice * split.Ratio
+		adjustments = append(adjustments, adjustment)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	logger.Info("moved the alerts for a split", "symbol", split.Symbol, "ratio", split.Ratio, "count", len(adjustments))
+	return adjustments, nil
+}
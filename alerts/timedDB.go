@@ -0,0 +1,53 @@
+package alerts
+
+import (
+	"database/sql"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/magmasystems/SlackStockSlashCommand/metrics"
+)
+
+var dbQueryDuration = metrics.CreateHistogram("stockbot_db_query_duration_seconds",
+	"How long the queries of the alert manager take, by statement and table.", nil, "statement", "table")
+
+// The first table of the schema that a statement touches
+var statementTable = regexp.MustCompile(`slackstockbot\.(\w+)`)
+
+// timedDB - the database of the AlertManager, which measures how long each query takes
+type timedDB struct {
+	*sql.DB
+}
+
+// Query - runs a query that returns rows
+func (db *timedDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	defer dbQueryDuration.ObserveSince(time.Now(), queryLabels(query)...)
+	return db.DB.Query(query, args...)
+}
+
+// QueryRow - runs a query that returns at most one row
+func (db *timedDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	defer dbQueryDuration.ObserveSince(time.Now(), queryLabels(query)...)
+	return db.DB.QueryRow(query, args...)
+}
+
+// Exec - runs a statement that does not return rows
+func (db *timedDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	defer dbQueryDuration.ObserveSince(time.Now(), queryLabels(query)...)
+	return db.DB.Exec(query, args...)
+}
+
+// queryLabels - "select" and "alertsubscription" for a SELECT from slackstockbot.alertsubscription
+func queryLabels(query string) []string {
+	statement := "other"
+	if fields := strings.Fields(query); len(fields) > 0 {
+		statement = strings.ToLower(fields[0])
+	}
+
+	table := "none"
+	if match := statementTable.FindStringSubmatch(query); match != nil {
+		table = strings.ToLower(match[1])
+	}
+	return []string{statement, table}
+}
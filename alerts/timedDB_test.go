@@ -0,0 +1,23 @@
+package alerts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQueryLabels(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"SELECT DISTINCT symbol FROM slackstockbot.alertsubscription", []string{"select", "alertsubscription"}},
+		{"\nINSERT INTO slackstockbot.newsalert (teamid) VALUES ($1)", []string{"insert", "newsalert"}},
+		{`UPDATE slackstockbot.alertsubscription SET wasnotified = true`, []string{"update", "alertsubscription"}},
+		{"", []string{"other", "none"}},
+	}
+	for _, tt := range tests {
+		if got := queryLabels(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("queryLabels(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
@@ -1,101 +1,274 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	_ "github.com/lib/pq"
 
 	"github.com/magmasystems/SlackStockSlashCommand/alerts"
+	"github.com/magmasystems/SlackStockSlashCommand/commands"
 	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
+	"github.com/magmasystems/SlackStockSlashCommand/corporateevents"
+	fr "github.com/magmasystems/SlackStockSlashCommand/framework"
 	"github.com/magmasystems/SlackStockSlashCommand/framework/logging"
+	"github.com/magmasystems/SlackStockSlashCommand/markethours"
+	"github.com/magmasystems/SlackStockSlashCommand/metrics"
+	"github.com/magmasystems/SlackStockSlashCommand/preferences"
+	"github.com/magmasystems/SlackStockSlashCommand/referencedata"
 	"github.com/magmasystems/SlackStockSlashCommand/slackmessaging"
 	"github.com/magmasystems/SlackStockSlashCommand/stockbot"
+	"github.com/magmasystems/SlackStockSlashCommand/workspaces"
 	"github.com/nlopes/slack"
 )
 
+var logger = logging.For("application")
+
+// "/quote SAP.DE in USD" shows the price in another currency
+var quoteCurrencyPattern = regexp.MustCompile(`(?i)^(.*\S)\s+in\s+([a-z]{3})$`)
+
 var (
 	theBot                    *stockbot.Stockbot
 	theAlertManager           *alerts.AlertManager
-	priceBreachCheckingTicker *time.Ticker
+	priceBreachCheckIntervals chan time.Duration // a reload sends a new quoteCheckInterval to the price breach ticker
 	appSettings               *config.AppSettings
+	theExchangeCalendar       *markethours.ExchangeCalendar
+	ignoreMarketHours         bool // like the rest of the marketHours, only read when the bot starts
+	theCommandRegistry        *commands.Registry
+	theWorkspaceStore         *workspaces.WorkspaceStore
+	thePreferenceStore        *preferences.PreferenceStore
+	theEventCalendar          *corporateevents.EventCalendar
+	theReminderSchedule       *corporateevents.DailySchedule
+	theSplitSchedule          *corporateevents.DailySchedule
+	theLifecycle              *fr.Lifecycle
+
+	// Posts the notifications. The tests replace it to see what would have been posted.
+	theNotifier slackmessaging.NotifyFunc = slackmessaging.PostSlackNotificationToTeam
 )
 
+// How long the requests that are being answered have to finish when the application stops
+const requestDrainTimeout = 10 * time.Second
+
+// How long the whole shutdown can take. Heroku kills the process 30 seconds after SIGTERM.
+const shutdownTimeout = 25 * time.Second
+
 func main() {
-	logfileName := os.Getenv("LOGFILE")
-	if logfileName == "" {
-		logfileName = "./stockbot.log"
+	// The appSettings are read once, from appSettings.json, the environment and the command line,
+	// and every part of the bot is given the ones it needs
+	var err error
+	if appSettings, err = config.Load(os.Args[1:], os.Environ()); err != nil {
+		logger.Fatal("the appSettings are invalid", "err", err)
+	}
+
+	// The log goes to stdout, or to the file in LOGFILE
+	if logfileName := os.Getenv("LOGFILE"); logfileName != "" {
+		f, err := os.Create(logfileName)
+		if err != nil {
+			logger.Fatal("cannot create the logfile", "file", logfileName, "err", err)
+		}
+		defer f.Close()
+		logging.SetOutput(f)
+	}
+	if err := logging.Configure(appSettings.Logging); err != nil {
+		logger.Warn("the Logging settings are invalid", "err", err)
 	}
 
-	logging.Infof("Application: Creating the logfile [%s]\n", logfileName)
-	f, _ := os.Create(logfileName)
-	defer f.Close()
-	log.SetOutput(f)
+	logger.Info("creating the stockbot", "driver", appSettings.Driver)
+
+	theLifecycle = fr.CreateLifecycle()
 
-	logging.Infoln("Application: About to create the Stockbot")
+	slackmessaging.Configure(appSettings)
 
-	theBot = stockbot.CreateStockbot()
-	defer theBot.Close()
+	theBot = stockbot.CreateStockbot(appSettings, nil)
+	theLifecycle.Own("stockbot", fr.DisposeFunc(theBot.Close))
 
 	go func() {
 		theBot.QuoteSingleAsync("MSFT")
 		quoteInfo := <-theBot.QuoteReceived
-		fmt.Println(quoteInfo[0])
+		if len(quoteInfo) == 0 {
+			logger.Warn("the first quote came back empty")
+			return
+		}
+		logger.Info("got the first quote", "symbol", quoteInfo[0].Symbol, "price", quoteInfo[0].LastPrice)
 	}()
 
-	configMgr := new(config.ConfigManager)
-	appSettings = configMgr.Config()
-	logging.Infof("Application: Got the app settings: the port is %d\n", appSettings.Port)
+	logger.Info("got the app settings", "port", appSettings.Port)
+
+	// The signing secret verifies the requests from Slack. Socket Mode does not need it, because the connection is outbound,
+	// and the Slack endpoints are not served.
+	signingSecret := appSettings.SlackSecret
 
-	// Get the signing secret from the config
-	var signingSecret string
-	signingSecret = appSettings.SlackSecret
-	if signingSecret == "" {
-		logging.Fatal("Application: The signing secret is not in the appSettings.json file")
+	// The exchange calendar tells us when it is worth checking for price breaches
+	if theExchangeCalendar, err = markethours.CreateExchangeCalendar(appSettings.MarketHours); err != nil {
+		logger.Fatal("the MarketHours settings are invalid", "err", err)
 	}
+	logger.Info("following the trading hours of the exchange", "exchange", theExchangeCalendar.Name)
+	ignoreMarketHours = appSettings.MarketHours.IgnoreHours
+
+	// The workspaces, the user preferences and the names of the symbols are kept in the same database as the alerts
+	db, err := sql.Open("postgres", appSettings.DatabaseConnectionInfo())
+	if err != nil {
+		logger.Fatal("cannot open the database", "err", err)
+	}
+	theLifecycle.Own("database", fr.DisposeFunc(func() { db.Close() }))
+
+	thePreferenceStore = preferences.CreatePreferenceStore(db)
+	theBot.SetSymbolStore(referencedata.CreateSymbolStore(db))
 
 	// Create the AlertManager
-	logging.Infoln("Application: About to create the Alert Manager")
-	theAlertManager = alerts.CreateAlertManager(theBot)
-	defer theAlertManager.Dispose()
-	logging.Infoln("Application: Created the Alert Manager")
-
-	// The HTTP request handler
-	http.HandleFunc("/quote", func(w http.ResponseWriter, r *http.Request) {
-		handleHTTPRequest(w, r, signingSecret)
-	})
+	theAlertManager = alerts.CreateAlertManager(appSettings, db, theBot, thePreferenceStore, theNotifier)
+	theLifecycle.Own("alert manager", theAlertManager)
+
+	// The earnings, dividends and splits of the alerted symbols
+	theEventCalendar = corporateevents.CreateEventCalendar(theBot, 0)
+	theReminderSchedule = corporateevents.CreateDailySchedule(appSettings.EventReminderHour, 17)
+	theSplitSchedule = corporateevents.CreateDailySchedule(splitCheckHour, splitCheckHour)
+
+	// If other workspaces can install the bot, each of them has its own bot token
+	if appSettings.OAuth.ClientID != "" {
+		if appSettings.OAuth.HomeTeamID == "" {
+			logger.Warn("the oauth homeTeamId is not set, so the slackBotToken and the webhooks are only used for the alerts from before OAuth")
+		}
+		theWorkspaceStore = workspaces.CreateWorkspaceStore(db)
+		slackmessaging.SetBotTokenLookup(theWorkspaceStore.BotToken)
+
+		oauthHandler := workspaces.CreateOAuthHandler(appSettings.OAuth, appSettings.SlackAPIURL, theWorkspaceStore)
+		http.HandleFunc("/slack/install", oauthHandler.HandleInstall)
+		http.HandleFunc("/slack/oauth_redirect", oauthHandler.HandleRedirect)
+		logger.Info("other workspaces can install the bot", "path", "/slack/install")
+	}
+
+	// Prometheus scrapes the counters and latencies here
+	http.Handle("/metrics", metrics.DefaultRegistry)
+
+	// The probes of the load balancer
+	liveness, readiness := createHealthCheckers(db, time.Now())
+	http.Handle("/healthz", liveness)
+	http.Handle("/readyz", readiness)
+
+	// The slash commands that the HTTP request handler dispatches to
+	theCommandRegistry = createCommandRegistry()
+
+	// With Socket Mode, the slash commands, interactions and events come over a WebSocket instead.
+	// The public port then has no Slack endpoints, as there may be no signing secret to verify their requests with.
+	if !useSocketMode() {
+		// The HTTP request handler
+		http.HandleFunc("/quote", func(w http.ResponseWriter, r *http.Request) {
+			handleHTTPRequest(w, r, signingSecret)
+		})
+
+		// The Events API Request URL, which Slack calls when the bot is mentioned or a message is posted
+		http.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+			handleEventsRequest(w, r, signingSecret)
+		})
+
+		// The Interactivity Request URL, which Slack calls when a user clicks a button
+		http.HandleFunc("/interactive", func(w http.ResponseWriter, r *http.Request) {
+			handleInteraction(w, r, signingSecret)
+		})
+	} else {
+		logger.Info("receiving the slash commands through Socket Mode")
+		socketModeClient := slackmessaging.CreateSocketModeClient(appSettings.SlackAppToken, appSettings.SlackAPIURL, slackmessaging.SocketModeHandlers{
+			SlashCommand: theCommandRegistry.Dispatch,
+			Interaction:  dispatchInteraction,
+			Event:        dispatchEvent,
+		})
+
+		theLifecycle.Go("socket mode", socketModeClient.Run)
+	}
 
 	//postSlackNotification("UKBM681GV", "This is an unsolicited message from the quote alerter")
 
-	// Create a ticker that will continually check for a price breach
+	// Create a ticker that will continually check for a price breach.
+	// When the application stops, the check that is running is finished, notifications and all.
 	if !appSettings.DisablePriceBreachChecking {
-		logging.Infof("Application: About to create the Price Breach Ticker with interval %d \n", appSettings.QuoteCheckInterval)
-		priceBreachCheckingTicker = time.NewTicker(time.Duration(appSettings.QuoteCheckInterval) * time.Second)
-		defer priceBreachCheckingTicker.Stop()
+		logger.Info("starting the price breach ticker", "interval_seconds", appSettings.QuoteCheckInterval)
+		priceBreachCheckIntervals = make(chan time.Duration, 1)
+		interval := time.Duration(appSettings.QuoteCheckInterval) * time.Second
 
 		// Every time the ticker elapses, we check for a price breach
-		go func() {
-			for range priceBreachCheckingTicker.C {
-				logging.Infoln("Application: Ticker elapsed: checking price breaches")
-				onPriceBreachTickerElapsed()
+		theLifecycle.Go("price breach checker", func(stop <-chan struct{}) {
+			ticker := time.NewTicker(interval)
+			defer func() { ticker.Stop() }()
+			for {
+				select {
+				case <-stop:
+					return
+				case interval := <-priceBreachCheckIntervals:
+					// A stopped ticker never fires again, so it is replaced with one that has the new interval
+					ticker.Stop()
+					ticker = time.NewTicker(interval)
+				case <-ticker.C:
+					logger.Debug("the price breach ticker elapsed")
+					breachTicked(time.Now())
+					onPriceBreachTickerElapsed()
+				}
 			}
-		}()
+		})
 	}
 
-	// Get the port from the config file
-	port := appSettings.Port
-	if port == 0 {
-		port = 5000
+	// The daily jobs: the alerts of stocks that split are adjusted before the market opens, and
+	// users who asked for them get a DM the day before the corporate events of their alerted symbols
+	theLifecycle.RunTicker("daily jobs", 15*time.Minute, func(now time.Time) {
+		if theSplitSchedule.Due(now) {
+			adjustAlertsForSplits(now)
+		}
+		if theReminderSchedule.Due(now) {
+			sendEventReminders(now)
+		}
+	})
+
+	// The news alerts are checked less often than the prices, as news providers have small quotas
+	if newsCheckInterval := newsCheckInterval(appSettings.NewsCheckInterval); newsCheckInterval > 0 {
+		theLifecycle.RunTicker("news alerts", newsCheckInterval, checkNewsAlerts)
+	}
+
+	// A SIGHUP or a change to the appSettings file reloads the settings that can change while the bot runs
+	watchSettings()
+
+	port := currentSettings().Port
+
+	// Start the web server. It is the last part to be added, so it is the first to stop, and the requests
+	// that it is answering can still use everything else while they finish.
+	server := &http.Server{Addr: ":" + strconv.Itoa(port)}
+	theLifecycle.Own("http server", fr.DisposeFunc(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), requestDrainTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			logger.Warn("not every request finished", "err", err)
+		}
+	}))
+
+	serverFailed := make(chan error, 1)
+	go func() {
+		logger.Info("listening", "port", port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("the web server stopped", "err", err)
+			serverFailed <- err
+			theLifecycle.Shutdown(shutdownTimeout)
+		}
+	}()
+
+	// Run until Heroku, Kubernetes or Ctrl-C asks us to stop
+	if sig := theLifecycle.WaitForSignal(os.Interrupt, syscall.SIGTERM); sig != nil {
+		logger.Info("shutting down", "signal", sig)
 	}
+	theLifecycle.Shutdown(shutdownTimeout)
 
-	// Start the web server
-	logging.Infof("Application: Listening on port %d\n\n", port)
-	http.ListenAndServe(":"+strconv.Itoa(port), nil)
+	// A bot that could not serve has failed, and the exit code tells Heroku or Kubernetes so
+	select {
+	case err := <-serverFailed:
+		logger.Fatal("the bot stopped because the web server failed", "err", err)
+	default:
+	}
 }
 
 func postSlackNotification(notification alerts.PriceBreachNotification, outputText string) {
@@ -110,56 +283,65 @@ func postSlackNotification(notification alerts.PriceBreachNotification, outputTe
 		format.Color = "#FF0000"
 	}
 
-	slackmessaging.PostSlackNotificationFormatted(notification.SlackUserName, notification.Channel, format)
+	deliverNotification(notification.TeamID, notification.SlackUserName, notification.Channel, format)
+}
+
+// deliverNotification - sends an alert to the channel it was created for. The notification goes out with the bot token
+// of the workspace that the alert was created in, and the user may want all of their alerts as a DM.
+func deliverNotification(teamID string, userID string, alertChannel string, format slackmessaging.SlackMessageFormat) {
+	prefs := userPreferences(teamID, userID)
+	channel := prefs.NotificationChannel(alertChannel)
+	theNotifier(teamID, userID, channel, format)
 }
 
 func handleHTTPRequest(w http.ResponseWriter, r *http.Request, signingSecret string) {
 	slashCommand, err := slackmessaging.ProcessIncomingSlashCommand(r, w, signingSecret)
-	logging.Infof("The slash command is: [%s]\n", slashCommand)
+	logger.Debug("got a slash command", "command", slashCommand.Command, "text", slashCommand.Text)
 	if err != nil {
 		return
 	}
 
-	// See which slash command the message contains
-	switch slashCommand.Command {
-	case "/quote", "/quoted":
-		switch strings.ToUpper(slashCommand.Text) {
-		case "HELP":
-			helpQuoteCommand(w)
-		default:
-			getQuotes(slashCommand, w)
-		}
+	theCommandRegistry.Dispatch(slashCommand, w)
+}
 
-	case "/quote-alert", "/quoted-alert":
-		switch strings.ToUpper(slashCommand.Text) {
-		case "HELP":
-			helpQuoteAlertCommand(w)
-		case "CHECK":
-			checkForPriceBreaches(w)
-		default:
-			theAlertManager.HandleQuoteAlert(slashCommand, w)
-		}
+func handleInteraction(w http.ResponseWriter, r *http.Request, signingSecret string) {
+	callback, err := slackmessaging.ProcessIncomingInteraction(r, w, signingSecret)
+	if err != nil {
+		logger.Warn("the interaction cannot be processed", "err", err)
+		return
+	}
+
+	dispatchInteraction(callback, w)
+}
 
+// dispatchInteraction - hands a button click or a modal submission to the AlertManager
+func dispatchInteraction(callback slackmessaging.Interaction, w http.ResponseWriter) {
+	switch callback.Type {
+	case slack.InteractionTypeBlockActions:
+		theAlertManager.HandleInteraction(callback, w)
+	case slackmessaging.InteractionTypeViewSubmission:
+		theAlertManager.HandleViewSubmission(callback, w)
 	default:
-		// Unknown command
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(http.StatusOK)
 	}
 }
 
+// useSocketMode - the transport in the appSettings is "socket" instead of the default "http"
+func useSocketMode() bool {
+	return strings.EqualFold(currentSettings().Transport, "socket")
+}
+
 func getQuotes(slashCommand slack.SlashCommand, w http.ResponseWriter) {
 	outputText := ""
 
-	symbols := strings.Split(slashCommand.Text, ",")
+	symbols, currency := parseQuoteText(slashCommand.Text)
 	go func() {
 		theBot.QuoteAsync(symbols)
 	}()
 
 	select {
 	case quotes := <-theBot.QuoteReceived:
-		for _, q := range quotes {
-			outputText += fmt.Sprintf("%s: %3.2f\n", strings.ToUpper(q.Symbol), q.LastPrice)
-		}
-
+		outputText = formatQuotes(quotes, userPreferences(slashCommand.TeamID, slashCommand.UserID), currency)
 		slackmessaging.WriteResponse(w, outputText)
 
 	case <-time.After(3 * time.Second):
@@ -167,19 +349,109 @@ func getQuotes(slashCommand slack.SlashCommand, w http.ResponseWriter) {
 	}
 }
 
+// parseQuoteText - splits "SAP.DE,MSFT in USD" into the symbols and the currency that the prices should be shown in
+func parseQuoteText(text string) ([]string, string) {
+	currency := ""
+	text = strings.TrimSpace(text)
+	if match := quoteCurrencyPattern.FindStringSubmatch(text); match != nil {
+		text, currency = match[1], strings.ToUpper(match[2])
+	}
+
+	var symbols []string
+	for _, symbol := range strings.Split(text, ",") {
+		symbols = append(symbols, strings.TrimSpace(symbol))
+	}
+	return symbols, currency
+}
+
+// formatQuotes - puts each quote on its own line, with the number of decimals that the user wants.
+// The prices are converted into the currency, or into the user's currency if none is asked for.
+// Without either, each price is shown in the currency that its symbol is listed in.
+func formatQuotes(quotes []stockbot.QuoteInfo, prefs preferences.Preferences, currency string) string {
+	if currency == "" {
+		currency = prefs.Currency
+	}
+
+	outputText := ""
+
+	// Outside of regular hours, the price is either the last close or a pre-market price
+	label := theExchangeCalendar.PriceLabel(time.Now())
+	if label != "" {
+		label = " (" + label + ")"
+	}
+
+	for _, q := range quotes {
+		price, priceCurrency := float64(q.LastPrice), q.Currency
+		if price != 0 && currency != "" {
+			if converted, err := theBot.ConvertPrice(q.Symbol, price, currency); err == nil {
+				price, priceCurrency = converted, currency
+			} else {
+				logger.Warn("cannot convert the price", "symbol", q.Symbol, "currency", currency, "err", err)
+			}
+		}
+
+		outputText += fmt.Sprintf("%s: %s%s\n", describeSymbol(strings.ToUpper(q.Symbol), q.Name), formatMoney(prefs, price, priceCurrency), label)
+	}
+
+	return outputText
+}
+
 // onPriceBreachTickerElapsed - This gets called every time the Price Breach Ticker ticks
 func onPriceBreachTickerElapsed() {
-	fmt.Println("Checking for price breaches at " + time.Now().String())
+	// Stock prices do not move while the market is closed, so don't waste any of the provider's quota.
+	// Crypto trades around the clock, and FX around the clock on weekdays, so those are still checked.
+	now := time.Now()
+	var include func(symbol string) bool
+	if !ignoreMarketHours && !theExchangeCalendar.IsOpen(now) {
+		logger.Info("the exchange is closed, so only the crypto and FX alerts are checked", "exchange", theExchangeCalendar.Name, "session", theExchangeCalendar.Session(now))
+		include = func(symbol string) bool {
+			switch stockbot.NormalizeSymbol(symbol).AssetClass {
+			case stockbot.AssetCrypto:
+				return true
+			case stockbot.AssetFX:
+				return markethours.IsFXMarketOpen(now)
+			default:
+				return false
+			}
+		}
+	}
 
-	theAlertManager.CheckForPriceBreaches(theBot, func(notification alerts.PriceBreachNotification) {
-		logging.Infoln("The notification to Slack is:")
-		logging.Infoln(fmt.Sprint(notification))
-		outputText := fmt.Sprintf("%s has gone %s the target price of %3.2f. The current price is %3.2f.\n",
-			notification.Symbol, notification.Direction, notification.TargetPrice, notification.CurrentPrice)
-		postSlackNotification(notification, outputText)
+	logger.Debug("checking for price breaches", "at", now)
+
+	theAlertManager.CheckForPriceBreachesOf(theBot, include, func(notification alerts.PriceBreachNotification) {
+		logger.Info("the price of an alert was breached", "alert", notification.SubscriptionID, "symbol", notification.Symbol, "price", notification.CurrentPrice)
+		postSlackNotification(notification, priceBreachText(notification, now))
 	})
 }
 
+// priceBreachText - the text of a price breach notification, in the format that its user prefers
+func priceBreachText(notification alerts.PriceBreachNotification, at time.Time) string {
+	prefs := userPreferences(notification.TeamID, notification.SlackUserName)
+	var name string
+	if info := theBot.SymbolInfo(notification.Symbol); info != nil {
+		name = info.Name
+	}
+	return fmt.Sprintf("%s has gone %s the target price of %s. The current price is %s as of %s.\n",
+		describeSymbol(notification.Symbol, name), notification.Direction, formatMoney(prefs, notification.TargetPrice, notification.Currency),
+		formatMoney(prefs, notification.CurrentPrice, notification.Currency), prefs.FormatTime(at))
+}
+
+// describeSymbol - the symbol, followed by the company name if it is known
+func describeSymbol(symbol string, name string) string {
+	if name == "" {
+		return symbol
+	}
+	return fmt.Sprintf("%s (%s)", symbol, name)
+}
+
+// formatMoney - a price, followed by its currency unless it is in dollars
+func formatMoney(prefs preferences.Preferences, price float64, currency string) string {
+	if currency == "" || currency == "USD" {
+		return prefs.FormatPrice(price)
+	}
+	return prefs.FormatPrice(price) + " " + currency
+}
+
 // checkForPriceBreaches - this is called when we get a /quote-alert CHECK
 func checkForPriceBreaches(w http.ResponseWriter) {
 	// Get the latest quotes
@@ -198,34 +470,10 @@ func checkForPriceBreaches(w http.ResponseWriter) {
 	// Go through all of the price breaches and notify the Slack user
 	outputText := ""
 	for _, notification := range notifications {
-		outputText = fmt.Sprintf("%s has gone %s the target price of %3.2f. The current price is %3.2f.\n",
-			notification.Symbol, notification.Direction, notification.TargetPrice, notification.CurrentPrice)
+		outputText = priceBreachText(notification, time.Now())
 
 		// Do the notification to slack synchronously
 		postSlackNotification(notification, outputText)
-
-		go func() {
-			println(outputText)
-		}()
+		logger.Debug("posted a price breach", "alert", notification.SubscriptionID, "text", outputText)
 	}
 }
-
-func helpQuoteCommand(w http.ResponseWriter) {
-	text := `/quote symbol[,symbol,symbol,...]
-	Retrieves the current price one or more stocks. Each stock can be separated by a comma`
-	slackmessaging.WriteResponse(w, text)
-}
-
-func helpQuoteAlertCommand(w http.ResponseWriter) {
-	text := `/quote-alert [symbol price [below]] [symbol delete] [deleteall] [#channel]
-	Sets up a subscription to a price alert for the specified symbol. 
-	Examples:
-	  /quote-alert - lists all of the alerts you have
-	  /quote-alert MSFT 130 - sends an alert when Microsoft stock reaches $130
-	  /quote-alert MSFT 130 #myalerts - sends an alert  to the #myalert Slack channel when Microsoft stock reaches $130
-	  /quote-alert MSFT 130 BELOW - sends an alert when Microsoft stock goes below $130
-	  /quote-alert MSFT delete - removes the existing alert on MSFT stock that you have subscribed to
-	  /quote-alert deleteall - deletes all alerts that you have
-	`
-	slackmessaging.WriteResponse(w, text)
-}
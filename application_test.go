@@ -0,0 +1,48 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/magmasystems/SlackStockSlashCommand/slackmessaging"
+)
+
+func TestParseQuoteText(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		wantSymbols  []string
+		wantCurrency string
+	}{
+		{"one symbol", "MSFT", []string{"MSFT"}, ""},
+		{"several symbols", "MSFT, IBM,INTC", []string{"MSFT", "IBM", "INTC"}, ""},
+		{"in another currency", "SAP.DE in USD", []string{"SAP.DE"}, "USD"},
+		{"several symbols in another currency", "sap.de,msft IN eur", []string{"sap.de", "msft"}, "EUR"},
+		{"a symbol called IN", "IN", []string{"IN"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			symbols, currency := parseQuoteText(tt.text)
+			if !reflect.DeepEqual(symbols, tt.wantSymbols) || currency != tt.wantCurrency {
+				t.Errorf("parseQuoteText() = %v, %v, want %v, %v", symbols, currency, tt.wantSymbols, tt.wantCurrency)
+			}
+		})
+	}
+}
+
+func TestDeliverNotification(t *testing.T) {
+	type posted struct{ teamID, userID, channel, text string }
+	var got []posted
+	theNotifier = func(teamID string, userID string, channel string, format slackmessaging.SlackMessageFormat) {
+		got = append(got, posted{teamID, userID, channel, format.Text})
+	}
+	defer func() { theNotifier = slackmessaging.PostSlackNotificationToTeam }()
+
+	// Without a preference store, everyone gets the default preferences, which post to the channel of the alert
+	deliverNotification("T123", "U456", "#alerts", slackmessaging.SlackMessageFormat{Text: "MSFT is above 150"})
+
+	want := []posted{{"T123", "U456", "#alerts", "MSFT is above 150"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("deliverNotification() posted %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,192 @@
+// Package commands - a registry of the slash commands that the bot understands
+package commands
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/magmasystems/SlackStockSlashCommand/framework/logging"
+	"github.com/magmasystems/SlackStockSlashCommand/metrics"
+	"github.com/magmasystems/SlackStockSlashCommand/slackmessaging"
+	"github.com/nlopes/slack"
+)
+
+// Request - everything that a handler needs to answer a slash command
+type Request struct {
+	SlashCommand slack.SlashCommand
+	Writer       http.ResponseWriter
+	Args         string // the text that follows the subcommand. For the main handler, this is the whole text.
+	RequestID    string
+	Log          *logging.Logger // adds the request id to everything that is logged about the command
+}
+
+var logger = logging.For("commands")
+
+var commandsReceived = metrics.CreateCounter("stockbot_slash_commands_total",
+	"The slash commands, by command and subcommand. Unknown commands are counted as \"unknown\".", "command", "subcommand")
+
+// Handler - answers a slash command
+type Handler func(request *Request)
+
+// Subcommand - a word after the command that has its own handler, like "/quote-alert check"
+type Subcommand struct {
+	Name    string
+	Usage   string
+	Handler Handler
+	Alone   bool // only a match when it is the whole text, so that "/quote-alert CHECK 5" is still an alert on CHECK
+}
+
+// Command - a slash command, with its aliases, subcommands and usage text
+type Command struct {
+	Name        string
+	Aliases     []string
+	Usage       string // the arguments, e.g. "symbol[,symbol,symbol,...]"
+	Description string
+	Examples    []string // each example is "arguments - what it does"
+	Subcommands []Subcommand
+	Handler     Handler // handles the text when it does not start with a subcommand
+}
+
+// Registry - knows every command by its name and its aliases
+type Registry struct {
+	commands []*Command
+	byName   map[string]*Command
+}
+
+// CreateRegistry - creates an empty command registry
+func CreateRegistry() *Registry {
+	return &Registry{byName: make(map[string]*Command)}
+}
+
+// Register - adds a command. The names and aliases must be unique across all of the commands.
+func (registry *Registry) Register(command *Command) error {
+	if command.Handler == nil {
+		return fmt.Errorf("the command %s has no handler", command.Name)
+	}
+
+	names := append([]string{command.Name}, command.Aliases...)
+	for _, name := range names {
+		if _, exists := registry.byName[strings.ToLower(name)]; exists {
+			return fmt.Errorf("the command %s is already registered", name)
+		}
+	}
+
+	for _, sub := range command.Subcommands {
+		if strings.EqualFold(sub.Name, "help") {
+			return fmt.Errorf("the command %s cannot have its own help subcommand", command.Name)
+		}
+	}
+
+	for _, name := range names {
+		registry.byName[strings.ToLower(name)] = command
+	}
+	registry.commands = append(registry.commands, command)
+	return nil
+}
+
+// Lookup - finds a command by its name or one of its aliases
+func (registry *Registry) Lookup(name string) *Command {
+	return registry.byName[strings.ToLower(name)]
+}
+
+// Dispatch - calls the handler of a slash command. "help" is answered from the registry.
+func (registry *Registry) Dispatch(slashCommand slack.SlashCommand, w http.ResponseWriter) {
+	requestID := newRequestID()
+	log := logger.With("request_id", requestID)
+	log.Info("got a slash command", "command", slashCommand.Command, "team", slashCommand.TeamID, "user", slashCommand.UserID)
+
+	command := registry.Lookup(slashCommand.Command)
+	if command == nil {
+		log.Warn("the command is unknown", "command", slashCommand.Command)
+		commandsReceived.Inc("unknown", "")
+		slackmessaging.WriteResponse(w, registry.unknownCommandText(slashCommand.Command))
+		return
+	}
+
+	started := time.Now()
+	defer func() {
+		log.Debug("answered the slash command", "command", command.Name, "duration", time.Since(started))
+	}()
+
+	text := strings.TrimSpace(slashCommand.Text)
+	word, rest := splitFirstWord(text)
+	request := &Request{SlashCommand: slashCommand, Writer: w, Args: text, RequestID: requestID, Log: log}
+
+	if strings.EqualFold(word, "help") && rest == "" {
+		commandsReceived.Inc(command.Name, "help")
+		slackmessaging.WriteResponse(w, command.Help())
+		return
+	}
+
+	for _, sub := range command.Subcommands {
+		if strings.EqualFold(word, sub.Name) && (rest == "" || !sub.Alone) {
+			commandsReceived.Inc(command.Name, sub.Name)
+			request.Args = rest
+			sub.Handler(request)
+			return
+		}
+	}
+
+	commandsReceived.Inc(command.Name, "")
+	command.Handler(request)
+}
+
+// Help - the help text of a command, generated from its usage, examples and subcommands
+func (command *Command) Help() string {
+	var help strings.Builder
+
+	fmt.Fprintf(&help, "%s %s\n", command.Name, command.Usage)
+	if command.Description != "" {
+		fmt.Fprintf(&help, "\t%s\n", command.Description)
+	}
+
+	if len(command.Examples) > 0 || len(command.Subcommands) > 0 {
+		help.WriteString("\tExamples:\n")
+	}
+	for _, example := range command.Examples {
+		fmt.Fprintf(&help, "\t  %s %s\n", command.Name, example)
+	}
+	for _, sub := range command.Subcommands {
+		fmt.Fprintf(&help, "\t  %s %s - %s\n", command.Name, sub.Name, sub.Usage)
+	}
+	fmt.Fprintf(&help, "\t  %s help - shows this help\n", command.Name)
+
+	if len(command.Aliases) > 0 {
+		fmt.Fprintf(&help, "\tYou can also type %s\n", strings.Join(command.Aliases, " or "))
+	}
+
+	return help.String()
+}
+
+func (registry *Registry) unknownCommandText(name string) string {
+	var names []string
+	for _, command := range registry.commands {
+		names = append(names, command.Name)
+	}
+	sort.Strings(names)
+
+	return fmt.Sprintf("Sorry, I don't know the %s command. I understand %s. Add `help` to any of them to see how they work.",
+		name, strings.Join(names, ", "))
+}
+
+// newRequestID - a random id that ties together the log entries of a slash command
+func newRequestID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(id)
+}
+
+func splitFirstWord(text string) (string, string) {
+	fields := strings.SplitN(text, " ", 2)
+	if len(fields) == 1 {
+		return fields[0], ""
+	}
+	return fields[0], strings.TrimSpace(fields[1])
+}
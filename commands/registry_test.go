@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nlopes/slack"
+)
+
+func TestRegistry_Dispatch(t *testing.T) {
+	var handled string
+	var args string
+
+	registry := CreateRegistry()
+	err := registry.Register(&Command{
+		Name:        "/quote-alert",
+		Aliases:     []string{"/quoted-alert"},
+		Usage:       "[symbol price]",
+		Description: "Sets up a price alert.",
+		Examples:    []string{"MSFT 130 - alerts at $130"},
+		Subcommands: []Subcommand{{Name: "check", Usage: "checks now", Handler: func(r *Request) { handled, args = "check", r.Args }, Alone: true}},
+		Handler:     func(r *Request) { handled, args = "main", r.Args },
+	})
+	if err != nil {
+		t.Fatalf("Registry.Register() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		command     string
+		text        string
+		wantHandled string
+		wantArgs    string
+		wantText    string
+	}{
+		{"main handler", "/quote-alert", " MSFT 130 ", "main", "MSFT 130", ""},
+		{"alias", "/QUOTED-ALERT", "MSFT 130", "main", "MSFT 130", ""},
+		{"subcommand", "/quote-alert", "CHECK", "check", "", ""},
+		{"a subcommand that must be alone is a symbol otherwise", "/quote-alert", "CHECK 5", "main", "CHECK 5", ""},
+		{"help", "/quote-alert", "Help", "", "", "/quote-alert check - checks now"},
+		{"help is only a subcommand on its own", "/quote-alert", "help me", "main", "help me", ""},
+		{"unknown command", "/quotes", "MSFT", "", "", "Sorry, I don't know the /quotes command. I understand /quote-alert."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled, args = "", ""
+			w := httptest.NewRecorder()
+
+			registry.Dispatch(slack.SlashCommand{Command: tt.command, Text: tt.text}, w)
+
+			if handled != tt.wantHandled || args != tt.wantArgs {
+				t.Errorf("Registry.Dispatch() handled by %q with %q, want %q with %q", handled, args, tt.wantHandled, tt.wantArgs)
+			}
+			if w.Code != 200 {
+				t.Errorf("Registry.Dispatch() status = %d, want 200", w.Code)
+			}
+			if tt.wantText != "" {
+				msg := slack.Msg{}
+				json.Unmarshal(w.Body.Bytes(), &msg)
+				if !strings.Contains(msg.Text, tt.wantText) {
+					t.Errorf("Registry.Dispatch() text = %q, want it to contain %q", msg.Text, tt.wantText)
+				}
+			}
+		})
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	handler := func(r *Request) {}
+
+	tests := []struct {
+		name    string
+		command *Command
+		wantErr bool
+	}{
+		{"new command", &Command{Name: "/quote", Handler: handler}, false},
+		{"duplicate name", &Command{Name: "/QUOTE", Handler: handler}, true},
+		{"duplicate alias", &Command{Name: "/quoted", Aliases: []string{"/quote"}, Handler: handler}, true},
+		{"no handler", &Command{Name: "/quote-news"}, true},
+		{"help subcommand", &Command{Name: "/quote-info", Handler: handler, Subcommands: []Subcommand{{Name: "HELP", Handler: handler}}}, true},
+	}
+
+	registry := CreateRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := registry.Register(tt.command); (err != nil) != tt.wantErr {
+				t.Errorf("Registry.Register() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCommand_Help(t *testing.T) {
+	command := &Command{
+		Name:        "/quote",
+		Aliases:     []string{"/quoted"},
+		Usage:       "symbol[,symbol,symbol,...]",
+		Description: "Retrieves the current price one or more stocks.",
+		Examples:    []string{"MSFT - gets the price of Microsoft stock"},
+	}
+
+	want := "/quote symbol[,symbol,symbol,...]\n" +
+		"\tRetrieves the current price one or more stocks.\n" +
+		"\tExamples:\n" +
+		"\t  /quote MSFT - gets the price of Microsoft stock\n" +
+		"\t  /quote help - shows this help\n" +
+		"\tYou can also type /quoted\n"
+
+	if got := command.Help(); got != want {
+		t.Errorf("Command.Help() = %q, want %q", got, want)
+	}
+}
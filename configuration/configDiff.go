@@ -0,0 +1,97 @@
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"unicode"
+)
+
+// The settings whose values are never logged. A change to one of them is logged without the old and new values.
+var secretSettings = map[string]bool{
+	"SlackSecret":   true,
+	"SlackBotToken": true,
+	"SlackAppToken": true,
+	"Webhook":       true,
+	"DMWebhook":     true,
+	"APIKeys":       true,
+	"Password":      true,
+	"ClientSecret":  true,
+}
+
+// Diff - a line for each setting that is different in the new appSettings, like "quoteCheckInterval: 600 -> 60".
+// The secrets, like the API keys and the tokens, are only said to have changed.
+func Diff(old *AppSettings, new *AppSettings) []string {
+	var changes []string
+	diffValues("", false, reflect.ValueOf(*old), reflect.ValueOf(*new), &changes)
+	return changes
+}
+
+func diffValues(path string, secret bool, old reflect.Value, new reflect.Value, changes *[]string) {
+	switch old.Kind() {
+	case reflect.Struct:
+		for i := 0; i < old.NumField(); i++ {
+			field := old.Type().Field(i)
+			diffValues(joinPath(path, settingName(field.Name)), secret || secretSettings[field.Name], old.Field(i), new.Field(i), changes)
+		}
+
+	case reflect.Map:
+		keys := make(map[string]reflect.Value)
+		for _, key := range append(old.MapKeys(), new.MapKeys()...) {
+			keys[fmt.Sprint(key.Interface())] = key
+		}
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			oldValue, newValue := old.MapIndex(keys[name]), new.MapIndex(keys[name])
+			switch {
+			case !oldValue.IsValid():
+				*changes = append(*changes, fmt.Sprintf("%s: added", joinPath(path, name)))
+			case !newValue.IsValid():
+				*changes = append(*changes, fmt.Sprintf("%s: removed", joinPath(path, name)))
+			default:
+				diffValues(joinPath(path, name), secret, oldValue, newValue, changes)
+			}
+		}
+
+	default:
+		if reflect.DeepEqual(old.Interface(), new.Interface()) {
+			return
+		}
+		if secret {
+			*changes = append(*changes, fmt.Sprintf("%s: changed", path))
+		} else {
+			*changes = append(*changes, fmt.Sprintf("%s: %v -> %v", path, old.Interface(), new.Interface()))
+		}
+	}
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// settingName - the name of a field as it is written in appSettings.json, like apiKeys for APIKeys
+func settingName(field string) string {
+	if field == "OAuth" {
+		return "oauth"
+	}
+	runes := []rune(field)
+	for i := range runes {
+		// The last capital of an acronym starts the next word, like the W of DMWebhook
+		if i > 0 && i+1 < len(runes) && unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		if !unicode.IsUpper(runes[i]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
@@ -0,0 +1,60 @@
+package configuration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old := defaultSettings()
+	old.Driver = "quandl"
+	old.APIKeys = map[string]string{"quandl": "old-key", "worldtrading": "wtd-key"}
+	old.Webhook = "https://hooks.slack.com/services/1"
+	old.Database.Password = "old"
+
+	new := defaultSettings()
+	new.Driver = "alphavantage"
+	new.APIKeys = map[string]string{"quandl": "new-key", "alphavantage": "av-key"}
+	new.Webhook = "https://hooks.slack.com/services/2"
+	new.Database.Password = "new"
+	new.QuoteCheckInterval = 60
+	new.Logging.Packages = map[string]string{"alerts": "debug"}
+
+	want := []string{
+		"driver: quandl -> alphavantage",
+		"apiKeys.alphavantage: added",
+		"apiKeys.quandl: changed",
+		"apiKeys.worldtrading: removed",
+		"webhook: changed",
+		"database.password: changed",
+		"quoteCheckInterval: 600 -> 60",
+		"logging.packages.alerts: added",
+	}
+	if got := Diff(old, new); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %q, want %q", got, want)
+	}
+	if got := Diff(old, old); len(got) != 0 {
+		t.Errorf("Diff() of the same settings = %q, want nothing", got)
+	}
+}
+
+func TestSettingName(t *testing.T) {
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"Driver", "driver"},
+		{"APIKeys", "apiKeys"},
+		{"DMWebhook", "dmWebhook"},
+		{"FXDriver", "fxDriver"},
+		{"OAuth", "oauth"},
+		{"SSL", "ssl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if got := settingName(tt.field); got != tt.want {
+				t.Errorf("settingName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
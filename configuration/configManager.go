@@ -1,32 +1,28 @@
 package configuration
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"log"
-	"os"
 	"strings"
-)
-
-// ConfigManagerOps - the operations that the COnfigManager can perform
-type ConfigManagerOps interface {
-	Config() *AppSettings
-}
 
-// ConfigManager - handles configuration
-type ConfigManager struct {
-}
+	"github.com/magmasystems/SlackStockSlashCommand/framework/logging"
+)
 
 // AppSettings - config settings for the bot
 type AppSettings struct {
-	Driver      string
-	APIKeys     map[string]string
-	SlackSecret string
-	Webhook     string
-	DMWebhook   string
-	Port        int
-	Database    struct {
+	Driver        string
+	FXDriver      string            // where the exchange rates come from. Defaults to the Driver if it has rates, or exchangeratesapi.
+	AssetDrivers  map[string]string // the driver of an asset class (equity, etf, fx or crypto), if not the default one
+	APIKeys       map[string]string
+	SlackSecret   string
+	SlackBotToken string // if present, notifications are posted through the Web API instead of the webhooks
+	SlackAPIURL   string // only used to point the Web API client at a fake Slack server
+	SlackAppToken string // the app-level token (xapp-...) that Socket Mode connects with
+	Transport     string // "http" (the default) receives requests on the public port, "socket" uses Socket Mode
+	OAuth         OAuthSettings
+	Webhook       string
+	DMWebhook     string
+	Port          int
+	Database      struct {
 		User     string
 		Password string
 		Host     string
@@ -36,55 +32,55 @@ type AppSettings struct {
 	}
 	QuoteCheckInterval         int
 	DisablePriceBreachChecking bool
+	MarketHours                MarketHoursSettings
+	ExpandCashtags             bool // if true, $TICKER in any channel that the bot is in gets a quote in the thread
+	EventReminderHour          int  // the hour (1 to 23, server time) of the reminders of tomorrow's corporate events. 0 means the default, 17.
+	NewsCheckInterval          int  // the minutes between the checks of the news alerts. Defaults to 60. A negative number turns them off.
+	Logging                    logging.Config
 }
 
-// Config - get the config settings from appSettings.json
-func (mgr *ConfigManager) Config() *AppSettings {
-	appSettingsFileName := "appSettings.json"
+// OAuthSettings - the credentials of a Slack app that other workspaces can install.
+// If the ClientID is empty, the bot only serves the workspace of the SlackBotToken and the webhooks.
+type OAuthSettings struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string   // the public url of /slack/oauth_redirect, as registered with the Slack app
+	Scopes       []string // the bot scopes to ask for. Defaults to the ones that the bot uses.
+	HomeTeamID   string   // the team of the SlackBotToken and the webhooks. No other team's notifications are posted with them.
+}
 
-	// See if there is an "env" argument on the command line. If so, it can point to another config file.
-	if env := getArg("env", ""); env != "" {
-		s := fmt.Sprintf("appSettings.%s.json", env)
-		// see if this config file exists
-		if _, err := os.Stat(s); err == nil {
-			appSettingsFileName = s
-		} else if _, err = os.Stat("../" + s); err == nil {
-			appSettingsFileName = "../" + s
-		}
-	}
+// MarketHoursSettings - describes the trading calendar of the exchange that the bot follows
+type MarketHoursSettings struct {
+	Exchange      string   // one of the built-in calendars (NYSE, NASDAQ, LSE, XETRA). Defaults to NYSE.
+	TimeZone      string   // overrides the exchange's time zone, e.g. "America/New_York"
+	PreMarketOpen string   // "HH:MM" in the exchange's time zone
+	Open          string   // "HH:MM" in the exchange's time zone
+	Close         string   // "HH:MM" in the exchange's time zone
+	Holidays      []string // dates ("YYYY-MM-DD") on which the exchange is closed
+	IgnoreHours   bool     // if true, the price breach checker runs around the clock
+}
 
-	bytes, err := ioutil.ReadFile(appSettingsFileName)
-	if err != nil {
-		if bytes, err = ioutil.ReadFile("../" + appSettingsFileName); err != nil {
-			log.Fatalf("cannot find the %s file", appSettingsFileName)
-		}
+// DatabaseConnectionInfo - the postgres connection string for the Database settings.
+// A password from DATABASE_URL can have spaces, quotes or backslashes in it, so every value is quoted.
+func (settings *AppSettings) DatabaseConnectionInfo() string {
+	psqlInfo := fmt.Sprintf("host=%s port=%d dbname=%s",
+		quoteConnectionValue(settings.Database.Host), settings.Database.Port, quoteConnectionValue(settings.Database.DbName))
+	if settings.Database.User != "" {
+		psqlInfo += " user=" + quoteConnectionValue(settings.Database.User)
 	}
-
-	settings := new(AppSettings)
-	json.Unmarshal(bytes, &settings)
-
-	// In case it's omitted, we need a sensible value on the Quote Checking Interval
-	if settings.QuoteCheckInterval == 0 {
-		settings.QuoteCheckInterval = 600
+	if settings.Database.Password != "" {
+		psqlInfo += " password=" + quoteConnectionValue(settings.Database.Password)
 	}
-
-	return settings
-}
-
-func getArg(key string, defautVal string) string {
-	var val string
-
-	for idx, arg := range os.Args {
-		switch strings.ToLower(arg) {
-		case key:
-			val = os.Args[idx+1]
-			break
-		}
+	if settings.Database.SSL {
+		psqlInfo += " sslmode=require"
+	} else {
+		psqlInfo += " sslmode=disable"
 	}
 
-	if len(val) == 0 {
-		val = defautVal
-	}
+	return psqlInfo
+}
 
-	return val
+// quoteConnectionValue - a value of a postgres connection string, in single quotes, with its backslashes and quotes escaped
+func quoteConnectionValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
 }
@@ -0,0 +1,30 @@
+package configuration
+
+import "testing"
+
+func TestAppSettings_DatabaseConnectionInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     string
+		password string
+		ssl      bool
+		want     string
+	}{
+		{"no user", "", "", false, `host='localhost' port=5432 dbname='stockbot' sslmode=disable`},
+		{"user and password", "bot", "shh", true, `host='localhost' port=5432 dbname='stockbot' user='bot' password='shh' sslmode=require`},
+		{"a password with a space", "bot", "two words", false, `host='localhost' port=5432 dbname='stockbot' user='bot' password='two words' sslmode=disable`},
+		{"a password with a quote and a backslash", "bot", `it's\x`, false, `host='localhost' port=5432 dbname='stockbot' user='bot' password='it\'s\\x' sslmode=disable`},
+		{"a password that sets another option", "bot", "x sslmode=disable", true, `host='localhost' port=5432 dbname='stockbot' user='bot' password='x sslmode=disable' sslmode=require`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &AppSettings{}
+			settings.Database.Host, settings.Database.Port, settings.Database.DbName = "localhost", 5432, "stockbot"
+			settings.Database.User, settings.Database.Password, settings.Database.SSL = tt.user, tt.password, tt.ssl
+
+			if got := settings.DatabaseConnectionInfo(); got != tt.want {
+				t.Errorf("DatabaseConnectionInfo() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/magmasystems/SlackStockSlashCommand/alerts"
+	"github.com/magmasystems/SlackStockSlashCommand/commands"
+	"github.com/magmasystems/SlackStockSlashCommand/corporateevents"
+	"github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+	"github.com/magmasystems/SlackStockSlashCommand/slackmessaging"
+	"github.com/nlopes/slack"
+)
+
+// How far ahead /quote-events looks
+const eventHorizonDays = 30
+
+// The hour, in server time, at which the alerts of stocks that split are adjusted
+const splitCheckHour = 7
+
+// handleQuoteEvents - shows the coming earnings, ex-dividend dates and splits of the symbols,
+// or of the symbols that the user has alerts on if none are named
+func handleQuoteEvents(request *commands.Request) {
+	teamID, userID := request.SlashCommand.TeamID, request.SlashCommand.UserID
+
+	var symbols []string
+	for _, symbol := range strings.Split(request.Args, ",") {
+		if symbol = strings.ToUpper(strings.TrimSpace(symbol)); symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+	if len(symbols) == 0 {
+		symbols = theAlertManager.GetAlertedSymbolsOf(teamID, userID)
+		if len(symbols) == 0 {
+			slackmessaging.WriteResponse(request.Writer, "You have no alerts. Name some symbols, like `/quote-events MSFT,AAPL`")
+			return
+		}
+	}
+
+	answerSlowly(request, "Getting the events...", func() slack.Message {
+		events, err := theEventCalendar.Upcoming(symbols, eventHorizonDays)
+		if err != nil && len(events) == 0 {
+			return textMessage(fmt.Sprintf("Sorry, %s", err.Error()))
+		}
+		return textMessage(formatEvents(events, eventHorizonDays))
+	})
+}
+
+// formatEvents - puts each event on its own line, after its date
+func formatEvents(events []quoteproviders.CorporateEvent, days int) string {
+	if len(events) == 0 {
+		return fmt.Sprintf("There are no earnings, ex-dividend dates or splits in the next %d days", days)
+	}
+
+	outputText := ""
+	for _, event := range events {
+		outputText += fmt.Sprintf("%s - %s\n", event.Date.Format("Mon Jan 2"), corporateevents.Describe(event))
+	}
+	return outputText
+}
+
+// sendEventReminders - DMs the users who turned on events in /quote-settings about tomorrow's events
+func sendEventReminders(now time.Time) {
+	var watchers []corporateevents.Watcher
+	for _, user := range theAlertManager.GetAlertedSymbolsByUser() {
+		if userPreferences(user.TeamID, user.UserID).EventReminders {
+			watchers = append(watchers, corporateevents.Watcher{TeamID: user.TeamID, UserID: user.UserID, Symbols: user.Symbols})
+		}
+	}
+	if len(watchers) == 0 {
+		return
+	}
+
+	reminders := theEventCalendar.Reminders(watchers, now)
+	logger.Info("sending the reminders of tomorrow's corporate events", "reminders", len(reminders))
+
+	for _, reminder := range reminders {
+		var lines []string
+		for _, event := range reminder.Events {
+			lines = append(lines, corporateevents.Describe(event))
+		}
+
+		// An empty channel means a DM
+		theNotifier(reminder.Watcher.TeamID, reminder.Watcher.UserID, "", slackmessaging.SlackMessageFormat{
+			Title: "Tomorrow",
+			Text:  strings.Join(lines, "\n"),
+			Color: "#439FE0",
+		})
+	}
+}
+
+// adjustAlertsForSplits - moves the target prices of the alerts on stocks that split, and tells their owners
+func adjustAlertsForSplits(now time.Time) {
+	theAlertManager.AdjustForSplits(theBot, now, func(adjustment alerts.SplitAdjustment) {
+		theNotifier(adjustment.TeamID, adjustment.SlackUserName, "", slackmessaging.SlackMessageFormat{
+			Title:   "Alert adjusted for a split",
+			Text:    splitAdjustmentText(adjustment),
+			Color:   "#439FE0",
+			UseTime: true,
+		})
+	})
+}
+
+// splitAdjustmentText - explains to the owner of an alert why its target price changed
+func splitAdjustmentText(adjustment alerts.SplitAdjustment) string {
+	prefs := userPreferences(adjustment.TeamID, adjustment.SlackUserName)
+	return fmt.Sprintf("%s had a %s split on %s, so the target price of your alert %d was moved from %s to %s.",
+		adjustment.Split.Symbol, describeSplitRatio(adjustment.Split.Ratio), adjustment.Split.Date.Format("Jan 2"), adjustment.AlertID,
+		formatMoney(prefs, adjustment.OldPrice, adjustment.Currency), formatMoney(prefs, adjustment.NewPrice, adjustment.Currency))
+}
+
+// describeSplitRatio - 4 is a 4-for-1 split, and 0.1 is a 1-for-10 reverse split
+func describeSplitRatio(ratio float64) string {
+	if ratio < 1 {
+		return fmt.Sprintf("1-for-%s reverse", strconv.FormatFloat(1/ratio, 'f', -1, 64))
+	}
+	return fmt.Sprintf("%s-for-1", strconv.FormatFloat(ratio, 'f', -1, 64))
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+func TestFormatEvents(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []quoteproviders.CorporateEvent
+		want   string
+	}{
+		{"no events", nil, "There are no earnings, ex-dividend dates or splits in the next 30 days"},
+		{
+			"events",
+			[]quoteproviders.CorporateEvent{
+				{Symbol: "MSFT", Kind: quoteproviders.EventEarnings, Date: time.Date(2019, 7, 18, 0, 0, 0, 0, time.UTC), Detail: "EPS estimate 1.21 USD"},
+				{Symbol: "AAPL", Kind: quoteproviders.EventSplit, Date: time.Date(2020, 8, 31, 0, 0, 0, 0, time.UTC), Detail: "4-for-1 split"},
+			},
+			"Thu Jul 18 - MSFT reports earnings (EPS estimate 1.21 USD)\nMon Aug 31 - AAPL has a 4-for-1 split\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatEvents(tt.events, 30); got != tt.want {
+				t.Errorf("formatEvents() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeSplitRatio(t *testing.T) {
+	tests := []struct {
+		ratio float64
+		want  string
+	}{
+		{4, "4-for-1"},
+		{1.5, "1.5-for-1"},
+		{0.1, "1-for-10 reverse"},
+	}
+	for _, tt := range tests {
+		if got := describeSplitRatio(tt.ratio); got != tt.want {
+			t.Errorf("describeSplitRatio(%v) = %v, want %v", tt.ratio, got, tt.want)
+		}
+	}
+}
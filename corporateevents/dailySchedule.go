@@ -0,0 +1,39 @@
+package corporateevents
+
+import (
+	"sync"
+	"time"
+)
+
+// DailySchedule - decides when a daily job, like sending the reminders, runs, so that it runs once a day
+type DailySchedule struct {
+	hour    int
+	mutex   sync.Mutex
+	lastRun time.Time
+}
+
+// CreateDailySchedule - the job runs at the hour, from 1 to 23. Any other hour means the defaultHour.
+func CreateDailySchedule(hour int, defaultHour int) *DailySchedule {
+	if hour <= 0 || hour > 23 {
+		hour = defaultHour
+	}
+	return &DailySchedule{hour: hour}
+}
+
+// Due - true the first time that it is called at or after the hour of each day
+func (schedule *DailySchedule) Due(now time.Time) bool {
+	schedule.mutex.Lock()
+	defer schedule.mutex.Unlock()
+
+	if now.Hour() < schedule.hour {
+		return false
+	}
+	year, month, day := now.Date()
+	lastYear, lastMonth, lastDay := schedule.lastRun.Date()
+	if year == lastYear && month == lastMonth && day == lastDay {
+		return false
+	}
+
+	schedule.lastRun = now
+	return true
+}
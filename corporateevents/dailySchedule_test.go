@@ -0,0 +1,28 @@
+package corporateevents
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDailySchedule_Due(t *testing.T) {
+	schedule := CreateDailySchedule(0, 17)
+	day := func(d int, hour int) time.Time { return time.Date(2019, 7, d, hour, 30, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want bool
+	}{
+		{"before the hour", day(17, 16), false},
+		{"at the hour", day(17, 17), true},
+		{"later on the same day", day(17, 18), false},
+		{"the next morning", day(18, 9), false},
+		{"the next evening", day(18, 20), true},
+	}
+	for _, tt := range tests {
+		if got := schedule.Due(tt.now); got != tt.want {
+			t.Errorf("%s: DailySchedule.Due() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
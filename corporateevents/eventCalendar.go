@@ -0,0 +1,129 @@
+// Package corporateevents - the earnings, ex-dividend dates and splits of the symbols that users have alerts on
+package corporateevents
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/magmasystems/SlackStockSlashCommand/framework/logging"
+	"github.com/magmasystems/SlackStockSlashCommand/metrics"
+	q "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+var logger = logging.For("corporateevents")
+
+// Event calendars change rarely, and each refresh costs several provider calls per symbol
+const defaultRefreshInterval = 24 * time.Hour
+
+// EventCalendar - keeps the events of each symbol that has been asked for, and asks the provider again once a day
+type EventCalendar struct {
+	provider        q.EventsProvider
+	refreshInterval time.Duration
+	now             func() time.Time
+	mutex           sync.Mutex
+	entries         map[string]calendarEntry
+}
+
+type calendarEntry struct {
+	events    []q.CorporateEvent
+	fetchedAt time.Time
+}
+
+// CreateEventCalendar - creates a calendar on top of a provider. A zero refreshInterval means once a day.
+func CreateEventCalendar(provider q.EventsProvider, refreshInterval time.Duration) *EventCalendar {
+	if refreshInterval <= 0 {
+		refreshInterval = defaultRefreshInterval
+	}
+	return &EventCalendar{
+		provider:        provider,
+		refreshInterval: refreshInterval,
+		now:             time.Now,
+		entries:         make(map[string]calendarEntry),
+	}
+}
+
+// Upcoming - the events of the symbols from today through the given number of days, soonest first.
+// The symbols whose events cannot be fetched are left out, and the first error is returned along with the others.
+func (calendar *EventCalendar) Upcoming(symbols []string, days int) ([]q.CorporateEvent, error) {
+	first := dayOf(calendar.now())
+	last := first.AddDate(0, 0, days)
+	return calendar.between(symbols, first, last)
+}
+
+// On - the events of the symbols on a single day
+func (calendar *EventCalendar) On(symbols []string, day time.Time) ([]q.CorporateEvent, error) {
+	day = dayOf(day)
+	return calendar.between(symbols, day, day)
+}
+
+func (calendar *EventCalendar) between(symbols []string, first time.Time, last time.Time) ([]q.CorporateEvent, error) {
+	var found []q.CorporateEvent
+	var firstErr error
+
+	for _, symbol := range symbols {
+		events, err := calendar.eventsOf(symbol)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		for _, event := range events {
+			if !event.Date.Before(first) && !event.Date.After(last) {
+				found = append(found, event)
+			}
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].Date.Before(found[j].Date)
+	})
+	return found, firstErr
+}
+
+// eventsOf - the events of a symbol, from the provider if they are not cached or are too old
+func (calendar *EventCalendar) eventsOf(symbol string) ([]q.CorporateEvent, error) {
+	now := calendar.now()
+
+	calendar.mutex.Lock()
+	entry, ok := calendar.entries[symbol]
+	calendar.mutex.Unlock()
+	fresh := ok && now.Sub(entry.fetchedAt) < calendar.refreshInterval
+	metrics.CacheLookup("events", fresh)
+	if fresh {
+		return entry.events, nil
+	}
+
+	events, err := calendar.provider.FetchEvents(symbol)
+	if err != nil {
+		logger.Warn("cannot get the events", "symbol", symbol, "err", err)
+		return nil, err
+	}
+
+	calendar.mutex.Lock()
+	calendar.entries[symbol] = calendarEntry{events: events, fetchedAt: now}
+	calendar.mutex.Unlock()
+	return events, nil
+}
+
+// Describe - what happens on the day of the event, like "MSFT reports earnings (EPS estimate 1.21 USD)"
+func Describe(event q.CorporateEvent) string {
+	switch event.Kind {
+	case q.EventEarnings:
+		return fmt.Sprintf("%s reports earnings (%s)", event.Symbol, event.Detail)
+	case q.EventExDividend:
+		return fmt.Sprintf("%s goes ex-dividend (%s)", event.Symbol, event.Detail)
+	case q.EventSplit:
+		return fmt.Sprintf("%s has a %s", event.Symbol, event.Detail)
+	default:
+		return fmt.Sprintf("%s: %s %s", event.Symbol, event.Kind, event.Detail)
+	}
+}
+
+// dayOf - the events are dated at midnight UTC, so that is how the days of other times are compared with them
+func dayOf(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,86 @@
+package corporateevents
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	q "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+// fakeEventsProvider - returns fixed events, and counts how often it is asked
+type fakeEventsProvider struct {
+	events map[string][]q.CorporateEvent
+	calls  int
+}
+
+func (provider *fakeEventsProvider) FetchEvents(symbol string) ([]q.CorporateEvent, error) {
+	provider.calls++
+	events, ok := provider.events[symbol]
+	if !ok {
+		return nil, errors.New("unknown symbol " + symbol)
+	}
+	return events, nil
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func createTestCalendar(now time.Time) (*EventCalendar, *fakeEventsProvider) {
+	provider := &fakeEventsProvider{events: map[string][]q.CorporateEvent{
+		"MSFT": {
+			{Symbol: "MSFT", Kind: q.EventExDividend, Date: date(2019, 5, 15), Detail: "0.46 per share"},
+			{Symbol: "MSFT", Kind: q.EventEarnings, Date: date(2019, 7, 18), Detail: "EPS estimate 1.21 USD"},
+		},
+		"AAPL": {
+			{Symbol: "AAPL", Kind: q.EventEarnings, Date: date(2019, 7, 30), Detail: "EPS estimate 2.10 USD"},
+			{Symbol: "AAPL", Kind: q.EventExDividend, Date: date(2019, 8, 9), Detail: "0.77 per share"},
+		},
+	}}
+
+	calendar := CreateEventCalendar(provider, 0)
+	calendar.now = func() time.Time { return now }
+	return calendar, provider
+}
+
+func TestEventCalendar_Upcoming(t *testing.T) {
+	calendar, _ := createTestCalendar(time.Date(2019, 7, 10, 15, 0, 0, 0, time.UTC))
+
+	events, err := calendar.Upcoming([]string{"AAPL", "MSFT", "XXXX"}, 30)
+	if err == nil {
+		t.Errorf("EventCalendar.Upcoming() should report the symbol that cannot be fetched")
+	}
+
+	var got []string
+	for _, event := range events {
+		got = append(got, Describe(event))
+	}
+	want := []string{
+		"MSFT reports earnings (EPS estimate 1.21 USD)",
+		"AAPL reports earnings (EPS estimate 2.10 USD)",
+		"AAPL goes ex-dividend (0.77 per share)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EventCalendar.Upcoming() = %v, want %v", got, want)
+	}
+}
+
+func TestEventCalendar_RefreshesOnceADay(t *testing.T) {
+	now := time.Date(2019, 7, 10, 15, 0, 0, 0, time.UTC)
+	calendar, provider := createTestCalendar(now)
+	calendar.now = func() time.Time { return now }
+
+	calendar.Upcoming([]string{"MSFT"}, 30)
+	calendar.Upcoming([]string{"MSFT"}, 30)
+	if provider.calls != 1 {
+		t.Errorf("the provider was called %d times, want 1", provider.calls)
+	}
+
+	now = now.Add(25 * time.Hour)
+	calendar.Upcoming([]string{"MSFT"}, 30)
+	if provider.calls != 2 {
+		t.Errorf("the provider was called %d times after a day, want 2", provider.calls)
+	}
+}
@@ -0,0 +1,35 @@
+package corporateevents
+
+import (
+	"time"
+
+	q "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+// Watcher - a user and the symbols that they have alerts on
+type Watcher struct {
+	TeamID  string
+	UserID  string
+	Symbols []string
+}
+
+// Reminder - the events of tomorrow that a user should be told about
+type Reminder struct {
+	Watcher Watcher
+	Events  []q.CorporateEvent
+}
+
+// Reminders - for each watcher, the events of their symbols on the day after today.
+// Watchers with nothing happening tomorrow are left out.
+func (calendar *EventCalendar) Reminders(watchers []Watcher, today time.Time) []Reminder {
+	tomorrow := dayOf(today).AddDate(0, 0, 1)
+
+	var reminders []Reminder
+	for _, watcher := range watchers {
+		events, _ := calendar.On(watcher.Symbols, tomorrow)
+		if len(events) > 0 {
+			reminders = append(reminders, Reminder{Watcher: watcher, Events: events})
+		}
+	}
+	return reminders
+}
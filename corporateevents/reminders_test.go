@@ -0,0 +1,23 @@
+package corporateevents
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventCalendar_Reminders(t *testing.T) {
+	calendar, _ := createTestCalendar(time.Date(2019, 7, 17, 17, 0, 0, 0, time.UTC))
+
+	watchers := []Watcher{
+		{TeamID: "T1", UserID: "U1", Symbols: []string{"MSFT", "AAPL"}},
+		{TeamID: "T1", UserID: "U2", Symbols: []string{"AAPL"}},
+	}
+	reminders := calendar.Reminders(watchers, time.Date(2019, 7, 17, 17, 0, 0, 0, time.UTC))
+
+	if len(reminders) != 1 {
+		t.Fatalf("EventCalendar.Reminders() = %v, want one reminder", reminders)
+	}
+	if reminders[0].Watcher.UserID != "U1" || len(reminders[0].Events) != 1 || reminders[0].Events[0].Symbol != "MSFT" {
+		t.Errorf("EventCalendar.Reminders() = %+v, want the MSFT earnings for U1", reminders[0])
+	}
+}
@@ -0,0 +1,127 @@
+package framework
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/magmasystems/SlackStockSlashCommand/framework/logging"
+)
+
+var logger = logging.For("lifecycle")
+
+// DisposeFunc - lets a function, like the Close of a Stockbot, be used as a Disposable
+type DisposeFunc func()
+
+// Dispose - calls the function
+func (dispose DisposeFunc) Dispose() {
+	dispose()
+}
+
+type ownedDisposable struct {
+	name       string
+	disposable Disposable
+}
+
+// Lifecycle - owns the parts of the application that have to be cleaned up when it stops.
+// They are disposed in the reverse of the order that they were added in, so whatever is added last,
+// like the HTTP server, stops first, and whatever it depends on is still there while it drains.
+type Lifecycle struct {
+	mutex    sync.Mutex
+	owned    []ownedDisposable
+	shutdown sync.Once
+	done     chan struct{} // closed when the shutdown starts
+	stopped  chan struct{} // closed when everything has been disposed of
+}
+
+// CreateLifecycle - creates a lifecycle that owns nothing yet
+func CreateLifecycle() *Lifecycle {
+	return &Lifecycle{done: make(chan struct{}), stopped: make(chan struct{})}
+}
+
+// Own - adds something that is disposed when the application stops
+func (lifecycle *Lifecycle) Own(name string, disposable Disposable) {
+	lifecycle.mutex.Lock()
+	defer lifecycle.mutex.Unlock()
+	lifecycle.owned = append(lifecycle.owned, ownedDisposable{name: name, disposable: disposable})
+}
+
+// Go - runs a background job, like a ticker loop, until the stop channel is closed. When the application stops,
+// the channel is closed and the lifecycle waits for the job to return, so a job should finish the work
+// that it is in the middle of and then return.
+func (lifecycle *Lifecycle) Go(name string, job func(stop <-chan struct{})) {
+	stop := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		job(stop)
+	}()
+
+	lifecycle.Own(name, DisposeFunc(func() {
+		close(stop)
+		<-finished
+	}))
+}
+
+// RunTicker - a job that calls the function every interval, with the time of the tick
+func (lifecycle *Lifecycle) RunTicker(name string, interval time.Duration, tick func(now time.Time)) {
+	lifecycle.Go(name, func(stop <-chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				tick(now)
+			}
+		}
+	})
+}
+
+// WaitForSignal - blocks until the process gets one of the signals, or until Shutdown is called, and returns the signal
+func (lifecycle *Lifecycle) WaitForSignal(signals ...os.Signal) os.Signal {
+	received := make(chan os.Signal, 1)
+	signal.Notify(received, signals...)
+	defer signal.Stop(received)
+
+	select {
+	case sig := <-received:
+		return sig
+	case <-lifecycle.done:
+		return nil
+	}
+}
+
+// Shutdown - disposes of everything that is owned, the last one first. If that takes longer than the timeout,
+// Shutdown returns anyway so that the process can exit. It returns false if it timed out.
+func (lifecycle *Lifecycle) Shutdown(timeout time.Duration) bool {
+	lifecycle.shutdown.Do(func() {
+		close(lifecycle.done)
+
+		lifecycle.mutex.Lock()
+		owned := lifecycle.owned
+		lifecycle.owned = nil
+		lifecycle.mutex.Unlock()
+
+		go func() {
+			defer close(lifecycle.stopped)
+			for i := len(owned) - 1; i >= 0; i-- {
+				logger.Info("stopping", "part", owned[i].name)
+				owned[i].disposable.Dispose()
+			}
+		}()
+	})
+
+	select {
+	case <-lifecycle.stopped:
+		logger.Info("stopped everything")
+		return true
+	case <-time.After(timeout):
+		logger.Error("the shutdown timed out", "timeout", timeout)
+		return false
+	}
+}
@@ -0,0 +1,68 @@
+package framework
+
+import (
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLifecycle_Shutdown(t *testing.T) {
+	lifecycle := CreateLifecycle()
+
+	var mutex sync.Mutex
+	var order []string
+	record := func(name string) {
+		mutex.Lock()
+		order = append(order, name)
+		mutex.Unlock()
+	}
+
+	lifecycle.Own("stockbot", DisposeFunc(func() { record("stockbot") }))
+
+	// The job is in the middle of a cycle when the shutdown starts, and finishes it
+	started := make(chan struct{})
+	lifecycle.Go("breach checker", func(stop <-chan struct{}) {
+		close(started)
+		<-stop
+		time.Sleep(10 * time.Millisecond)
+		record("breach checker")
+	})
+	<-started
+
+	lifecycle.Own("http server", DisposeFunc(func() { record("http server") }))
+
+	if !lifecycle.Shutdown(time.Second) {
+		t.Fatal("Lifecycle.Shutdown() timed out")
+	}
+	want := []string{"http server", "breach checker", "stockbot"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("the parts stopped in the order %v, want %v", order, want)
+	}
+
+	// A second shutdown has nothing left to do
+	if !lifecycle.Shutdown(time.Second) {
+		t.Error("the second Lifecycle.Shutdown() timed out")
+	}
+}
+
+func TestLifecycle_ShutdownTimeout(t *testing.T) {
+	lifecycle := CreateLifecycle()
+	release := make(chan struct{})
+	defer close(release)
+	lifecycle.Own("stuck", DisposeFunc(func() { <-release }))
+
+	if lifecycle.Shutdown(10 * time.Millisecond) {
+		t.Error("Lifecycle.Shutdown() did not time out")
+	}
+}
+
+func TestLifecycle_WaitForSignal(t *testing.T) {
+	lifecycle := CreateLifecycle()
+	go lifecycle.Shutdown(time.Second)
+
+	if sig := lifecycle.WaitForSignal(os.Interrupt); sig != nil {
+		t.Errorf("Lifecycle.WaitForSignal() = %v, want nil after a shutdown", sig)
+	}
+}
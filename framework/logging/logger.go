@@ -1,39 +1,290 @@
 package logging
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
-	"log"
+	"io"
+	"os"
+	"strings"
+	"sync"
 	"time"
 )
 
-// Infoln - prints a line to the log and to the console
+// Level - how important a log entry is
+type Level int
+
+// The levels, from the most to the least verbose
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (level Level) String() string {
+	if level < LevelDebug || level > LevelError {
+		return fmt.Sprintf("level(%d)", int(level))
+	}
+	return levelNames[level]
+}
+
+// ParseLevel - "debug", "info", "warn" or "error". An empty name is info.
+func ParseLevel(name string) (Level, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return LevelInfo, nil
+	}
+	if name == "warning" {
+		return LevelWarn, nil
+	}
+	for level, levelName := range levelNames {
+		if name == levelName {
+			return Level(level), nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("%s is not a log level (expected debug, info, warn or error)", name)
+}
+
+// Config - the logging section of the appSettings
+type Config struct {
+	Level    string            // the level of every package that is not in Packages. Defaults to info.
+	Format   string            // "text" (the default) or "json", which writes one JSON object per line
+	Packages map[string]string // the level of a package, like "alerts": "debug"
+}
+
+// Validate - the errors in the level names and the format
+func (config Config) Validate() []error {
+	var errs []error
+	if _, err := ParseLevel(config.Level); err != nil {
+		errs = append(errs, err)
+	}
+	for pkg, name := range config.Packages {
+		if _, err := ParseLevel(name); err != nil {
+			errs = append(errs, fmt.Errorf("the level of %s: %s", pkg, err.Error()))
+		}
+	}
+	if format := strings.ToLower(config.Format); format != "" && format != "text" && format != "json" {
+		errs = append(errs, fmt.Errorf("%s is not a log format (expected text or json)", config.Format))
+	}
+	return errs
+}
+
+// The settings that every Logger shares
+var (
+	settingsLock  sync.RWMutex
+	output        io.Writer = os.Stdout
+	defaultLevel            = LevelInfo
+	packageLevels           = map[string]Level{}
+	jsonFormat    bool
+	now           = time.Now
+)
+
+// Configure - sets the levels and the format. Levels that cannot be parsed are reported and left at info.
+func Configure(config Config) error {
+	levels := make(map[string]Level)
+	for pkg, name := range config.Packages {
+		levels[strings.ToLower(pkg)], _ = ParseLevel(name)
+	}
+	level, _ := ParseLevel(config.Level)
+
+	settingsLock.Lock()
+	defaultLevel = level
+	packageLevels = levels
+	jsonFormat = strings.EqualFold(config.Format, "json")
+	settingsLock.Unlock()
+
+	if errs := config.Validate(); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// SetOutput - where the log entries are written. Defaults to stdout.
+func SetOutput(writer io.Writer) {
+	settingsLock.Lock()
+	output = writer
+	settingsLock.Unlock()
+}
+
+// Logger - writes leveled log entries for a package, with key-value fields
+type Logger struct {
+	pkg    string
+	fields []interface{}
+}
+
+// For - the logger of a package. The level of the package can be set in the Packages of the Config.
+func For(pkg string) *Logger {
+	return &Logger{pkg: pkg}
+}
+
+// With - a logger that adds the key-value pairs to every entry, like log.With("request_id", id)
+func (logger *Logger) With(keyvals ...interface{}) *Logger {
+	logger = logger.orDefault()
+	fields := make([]interface{}, 0, len(logger.fields)+len(keyvals))
+	fields = append(fields, logger.fields...)
+	fields = append(fields, keyvals...)
+	return &Logger{pkg: logger.pkg, fields: fields}
+}
+
+// Enabled - whether entries of the level are written for the package
+func (logger *Logger) Enabled(level Level) bool {
+	logger = logger.orDefault()
+	settingsLock.RLock()
+	defer settingsLock.RUnlock()
+
+	threshold, ok := packageLevels[strings.ToLower(logger.pkg)]
+	if !ok {
+		threshold = defaultLevel
+	}
+	return level >= threshold
+}
+
+// Debug - details that are only useful when tracking down a problem
+func (logger *Logger) Debug(msg string, keyvals ...interface{}) {
+	logger.log(LevelDebug, msg, keyvals)
+}
+
+// Info - the normal progress of the bot
+func (logger *Logger) Info(msg string, keyvals ...interface{}) {
+	logger.log(LevelInfo, msg, keyvals)
+}
+
+// Warn - something went wrong, but the bot carried on
+func (logger *Logger) Warn(msg string, keyvals ...interface{}) {
+	logger.log(LevelWarn, msg, keyvals)
+}
+
+// Error - something failed, and a user or an operator will notice
+func (logger *Logger) Error(msg string, keyvals ...interface{}) {
+	logger.log(LevelError, msg, keyvals)
+}
+
+// Fatal - something failed that the bot cannot run without. Writes the entry at the error level and exits.
+func (logger *Logger) Fatal(msg string, keyvals ...interface{}) {
+	logger.log(LevelError, msg, keyvals)
+	os.Exit(1)
+}
+
+func (logger *Logger) log(level Level, msg string, keyvals []interface{}) {
+	logger = logger.orDefault()
+	if !logger.Enabled(level) {
+		return
+	}
+
+	fields := append(append([]interface{}{}, logger.fields...), keyvals...)
+	if len(fields)%2 != 0 {
+		fields = append(fields, "(missing)")
+	}
+
+	// The lock is exclusive so that the entries of different goroutines are not interleaved
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+
+	var entry []byte
+	if jsonFormat {
+		entry = formatJSON(now(), level, logger.pkg, msg, fields)
+	} else {
+		entry = formatText(now(), level, logger.pkg, msg, fields)
+	}
+	output.Write(entry)
+}
+
+// orDefault - a nil Logger, like the one of a request that did not come through the command registry, logs without a package
+func (logger *Logger) orDefault() *Logger {
+	if logger == nil {
+		return std
+	}
+	return logger
+}
+
+// formatText - 2019-10-01T12:00:00Z INFO  alerts: created the alert id=12 symbol=MSFT
+func formatText(at time.Time, level Level, pkg string, msg string, fields []interface{}) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %-5s ", at.Format(time.RFC3339), strings.ToUpper(level.String()))
+	if pkg != "" {
+		buf.WriteString(pkg + ": ")
+	}
+	buf.WriteString(msg)
+
+	for i := 0; i < len(fields); i += 2 {
+		value := fmt.Sprint(fieldValue(fields[i+1]))
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = fmt.Sprintf("%q", value)
+		}
+		fmt.Fprintf(&buf, " %v=%s", fields[i], value)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// formatJSON - {"time":"2019-10-01T12:00:00Z","level":"info","package":"alerts","msg":"created the alert","id":12}
+func formatJSON(at time.Time, level Level, pkg string, msg string, fields []interface{}) []byte {
+	var buf bytes.Buffer
+	writePair := func(key string, value interface{}) {
+		encodedKey, _ := json.Marshal(key)
+		encodedValue, err := json.Marshal(value)
+		if err != nil {
+			encodedValue, _ = json.Marshal(fmt.Sprint(value))
+		}
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+		buf.Write(encodedValue)
+	}
+
+	buf.WriteByte('{')
+	writePair("time", at.Format(time.RFC3339))
+	buf.WriteByte(',')
+	writePair("level", level.String())
+	if pkg != "" {
+		buf.WriteByte(',')
+		writePair("package", pkg)
+	}
+	buf.WriteByte(',')
+	writePair("msg", msg)
+	for i := 0; i < len(fields); i += 2 {
+		buf.WriteByte(',')
+		writePair(fmt.Sprint(fields[i]), fieldValue(fields[i+1]))
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// fieldValue - errors and Stringers are logged as their text
+func fieldValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return value
+	}
+}
+
+// The logger of the packages that still use the functions below
+var std = For("")
+
+// Infoln - writes a line at the info level. New code should use a Logger.
 func Infoln(msg string) {
-	fmt.Printf("%s ", time.Now().Format(time.RFC3339))
-	fmt.Println(msg)
-	log.Printf("%s ", time.Now().Format(time.RFC3339))
-	log.Println(msg)
+	std.Info(strings.TrimRight(msg, "\n"))
 }
 
-// Infof - prints a formatted message to the log and to the console
+// Infof - writes a formatted message at the info level. New code should use a Logger.
 func Infof(msg string, args ...interface{}) {
-	fmt.Printf("%s ", time.Now().Format(time.RFC3339))
-	fmt.Printf(msg, args...)
-	log.Printf("%s ", time.Now().Format(time.RFC3339))
-	log.Printf(msg, args...)
+	std.Info(strings.TrimRight(fmt.Sprintf(msg, args...), "\n"))
 }
 
-// Fatal - prints a formatted message to the log and to the console
+// Fatal - writes the message at the error level and exits
 func Fatal(args ...interface{}) {
-	fmt.Printf("%s ", time.Now().Format(time.RFC3339))
-	fmt.Printf("Fatal error: %s", args)
-	log.Printf("%s ", time.Now().Format(time.RFC3339))
-	log.Fatal(args...)
+	std.Error(fmt.Sprint(args...))
+	os.Exit(1)
 }
 
-// Panic - prints a formatted message to the log and to the console
+// Panic - writes the error at the error level and panics with it
 func Panic(err error) {
-	fmt.Printf("%s ", time.Now().Format(time.RFC3339))
-	fmt.Printf("Fatal error: %s", fmt.Sprint(err))
-	log.Printf("%s ", time.Now().Format(time.RFC3339))
-	log.Fatal(fmt.Sprint(err))
+	std.Error(fmt.Sprint(err))
+	panic(err)
 }
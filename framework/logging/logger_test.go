@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// capture - configures the logging, and returns what is written until the function that it returns puts the logging back
+func capture(t *testing.T, config Config) (*bytes.Buffer, func()) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	if err := Configure(config); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	now = func() time.Time { return time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC) }
+	return &buf, func() {
+		now = time.Now
+		Configure(Config{})
+		SetOutput(os.Stdout)
+	}
+}
+
+func TestLogger_Text(t *testing.T) {
+	buf, restore := capture(t, Config{})
+	defer restore()
+
+	For("alerts").With("request_id", "abc").Info("created the alert", "symbol", "MSFT", "reason", "two words", "err", errors.New("boom"))
+
+	want := `2019-10-01T12:00:00Z INFO  alerts: created the alert request_id=abc symbol=MSFT reason="two words" err=boom` + "\n"
+	if buf.String() != want {
+		t.Errorf("the entry is %q, want %q", buf.String(), want)
+	}
+}
+
+func TestLogger_JSON(t *testing.T) {
+	buf, restore := capture(t, Config{Format: "json"})
+	defer restore()
+
+	For("stockbot").Warn("no provider", "class", "fx", "count", 2)
+
+	want := `{"time":"2019-10-01T12:00:00Z","level":"warn","package":"stockbot","msg":"no provider","class":"fx","count":2}` + "\n"
+	if buf.String() != want {
+		t.Errorf("the entry is %q, want %q", buf.String(), want)
+	}
+}
+
+func TestLogger_Levels(t *testing.T) {
+	buf, restore := capture(t, Config{Level: "warn", Packages: map[string]string{"alerts": "debug"}})
+	defer restore()
+
+	For("alerts").Debug("alerts debug")
+	For("stockbot").Info("stockbot info")
+	For("stockbot").Error("stockbot error")
+	Infof("legacy info\n")
+
+	got := buf.String()
+	if !strings.Contains(got, "alerts debug") || !strings.Contains(got, "stockbot error") {
+		t.Errorf("the enabled entries are missing from %q", got)
+	}
+	if strings.Contains(got, "stockbot info") || strings.Contains(got, "legacy info") {
+		t.Errorf("the disabled entries are in %q", got)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	config := Config{Level: "loud", Format: "xml", Packages: map[string]string{"alerts": "verbose"}}
+	if errs := config.Validate(); len(errs) != 3 {
+		t.Errorf("Config.Validate() = %v, want 3 errors", errs)
+	}
+	if errs := (Config{Level: "Debug", Format: "JSON", Packages: map[string]string{"alerts": "warning"}}).Validate(); len(errs) != 0 {
+		t.Errorf("Config.Validate() = %v, want no errors", errs)
+	}
+}
@@ -3,7 +3,7 @@ module github.com/magmasystems/SlackStockSlashCommand
 go 1.12
 
 require (
-	github.com/gorilla/websocket v1.4.0 // indirect
+	github.com/gorilla/websocket v1.4.0
 	github.com/lib/pq v1.1.1
 	github.com/nlopes/slack v0.5.1-0.20190622224905-50e524da56af
 	github.com/pkg/errors v0.8.1 // indirect
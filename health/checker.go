@@ -0,0 +1,109 @@
+// Package health - the checks behind /healthz and /readyz
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Result - the outcome of one check
+type Result struct {
+	Name      string    `json:"name"`
+	Healthy   bool      `json:"healthy"`
+	Detail    string    `json:"detail,omitempty"` // why the check failed, or what it found
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// CheckFunc - a check. It returns what it found, or an error if the dependency is not usable.
+type CheckFunc func() (string, error)
+
+type check struct {
+	name     string
+	run      CheckFunc
+	cacheFor time.Duration // zero means the check runs on every request
+
+	mutex  sync.Mutex
+	cached *Result
+}
+
+// Checker - runs a set of checks and answers with their results. The answer is 200 if all of them pass, and 503 if not.
+type Checker struct {
+	checks []*check
+	now    func() time.Time
+}
+
+// CreateChecker - creates a checker with no checks, which is always healthy
+func CreateChecker() *Checker {
+	return &Checker{now: time.Now}
+}
+
+// Add - adds a check that runs on every request
+func (checker *Checker) Add(name string, run CheckFunc) {
+	checker.AddCached(name, 0, run)
+}
+
+// AddCached - adds a check whose result is reused for a while, like one that costs some of the provider's quota
+func (checker *Checker) AddCached(name string, cacheFor time.Duration, run CheckFunc) {
+	checker.checks = append(checker.checks, &check{name: name, run: run, cacheFor: cacheFor})
+}
+
+// Run - the results of all of the checks, and whether they all passed
+func (checker *Checker) Run() ([]Result, bool) {
+	results := make([]Result, len(checker.checks))
+	healthy := true
+
+	var wg sync.WaitGroup
+	for i, c := range checker.checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			results[i] = c.result(checker.now())
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		healthy = healthy && result.Healthy
+	}
+	return results, healthy
+}
+
+// result - runs the check, unless its last result is still fresh
+func (c *check) result(now time.Time) Result {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.cached != nil && now.Sub(c.cached.CheckedAt) < c.cacheFor {
+		return *c.cached
+	}
+
+	result := Result{Name: c.name, Healthy: true, CheckedAt: now}
+	detail, err := c.run()
+	if err != nil {
+		result.Healthy = false
+		result.Detail = err.Error()
+	} else {
+		result.Detail = detail
+	}
+	c.cached = &result
+	return result
+}
+
+// ServeHTTP - answers a probe with the results as Json
+func (checker *Checker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	results, healthy := checker.Run()
+
+	status := "ok"
+	w.Header().Set("Content-Type", "application/json")
+	if !healthy {
+		status = "failing"
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	json.NewEncoder(w).Encode(struct {
+		Status string   `json:"status"`
+		Checks []Result `json:"checks"`
+	}{status, results})
+}
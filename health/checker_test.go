@@ -0,0 +1,99 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestChecker_ServeHTTP(t *testing.T) {
+	checker := CreateChecker()
+	checker.Add("slack", func() (string, error) { return "webhook", nil })
+	checker.Add("database", func() (string, error) { return "", errors.New("the database does not answer") })
+
+	recorder := httptest.NewRecorder()
+	checker.ServeHTTP(recorder, httptest.NewRequest("GET", "/readyz", nil))
+
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("the status is %d, want %d", recorder.Code, http.StatusServiceUnavailable)
+	}
+	var body struct {
+		Status string
+		Checks []Result
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("the body %s is not Json: %v", recorder.Body.String(), err)
+	}
+	if body.Status != "failing" || len(body.Checks) != 2 || !body.Checks[0].Healthy || body.Checks[1].Detail != "the database does not answer" {
+		t.Errorf("the body is %s", recorder.Body.String())
+	}
+}
+
+func TestChecker_AddCached(t *testing.T) {
+	now := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	checker := CreateChecker()
+	checker.now = func() time.Time { return now }
+
+	calls := 0
+	checker.AddCached("provider", 10*time.Minute, func() (string, error) {
+		calls++
+		return "quoted MSFT", nil
+	})
+
+	checker.Run()
+	now = now.Add(5 * time.Minute)
+	checker.Run()
+	if calls != 1 {
+		t.Errorf("the check ran %d times within the cache time, want 1", calls)
+	}
+
+	now = now.Add(6 * time.Minute)
+	checker.Run()
+	if calls != 2 {
+		t.Errorf("the check ran %d times after the cache time, want 2", calls)
+	}
+}
+
+func TestRecent(t *testing.T) {
+	start := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	now := start.Add(10 * time.Minute)
+	clock := func() time.Time { return now }
+
+	tests := []struct {
+		name    string
+		last    time.Time
+		maxAge  time.Duration
+		healthy bool
+	}{
+		{"recent", now.Add(-time.Minute), 5 * time.Minute, true},
+		{"stale", now.Add(-6 * time.Minute), 5 * time.Minute, false},
+		{"not run yet, just started", time.Time{}, 15 * time.Minute, true},
+		{"never ran", time.Time{}, 5 * time.Minute, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			last := tt.last
+			_, err := Recent("breach check", func() time.Time { return last }, tt.maxAge, start, clock)()
+			if (err == nil) != tt.healthy {
+				t.Errorf("Recent() error = %v, want healthy %v", err, tt.healthy)
+			}
+		})
+	}
+}
+
+type fakePinger struct{ err error }
+
+func (pinger fakePinger) PingContext(ctx context.Context) error { return pinger.err }
+
+func TestDatabase(t *testing.T) {
+	if _, err := Database(fakePinger{}, time.Second)(); err != nil {
+		t.Errorf("Database() error = %v", err)
+	}
+	if _, err := Database(fakePinger{errors.New("connection refused")}, time.Second)(); err == nil {
+		t.Error("Database() did not fail when the ping did")
+	}
+}
@@ -0,0 +1,47 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Pinger - a database, or anything else that can be pinged
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Database - a check that pings the database, and fails if it does not answer within the timeout
+func Database(db Pinger, timeout time.Duration) CheckFunc {
+	return func() (string, error) {
+		if db == nil {
+			return "", fmt.Errorf("there is no database connection")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		if err := db.PingContext(ctx); err != nil {
+			return "", fmt.Errorf("the database does not answer: %s", err.Error())
+		}
+		return "the database answers", nil
+	}
+}
+
+// Recent - a check that fails if something has not happened for longer than maxAge, like a breach check.
+// Until it first happens, the age is counted from the start.
+func Recent(what string, last func() time.Time, maxAge time.Duration, start time.Time, now func() time.Time) CheckFunc {
+	return func() (string, error) {
+		at := last()
+		if at.IsZero() {
+			if age := now().Sub(start); age > maxAge {
+				return "", fmt.Errorf("the %s has not succeeded in the %s since the start", what, age.Round(time.Second))
+			}
+			return fmt.Sprintf("the %s has not run yet", what), nil
+		}
+
+		if age := now().Sub(at); age > maxAge {
+			return "", fmt.Errorf("the last successful %s was %s ago, at %s", what, age.Round(time.Second), at.Format(time.RFC3339))
+		}
+		return fmt.Sprintf("the last successful %s was at %s", what, at.Format(time.RFC3339)), nil
+	}
+}
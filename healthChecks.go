@@ -0,0 +1,67 @@
+package main
+
+import (
+	"database/sql"
+	"sync/atomic"
+	"time"
+
+	"github.com/magmasystems/SlackStockSlashCommand/health"
+	"github.com/magmasystems/SlackStockSlashCommand/slackmessaging"
+)
+
+// How long a provider ping is trusted for, since each one uses some of the provider's quota
+const providerCheckInterval = 10 * time.Minute
+
+// How long the database has to answer a ping
+const databaseCheckTimeout = 2 * time.Second
+
+// createHealthCheckers - /healthz fails if the price breach checker is stuck, which only a restart fixes.
+// It only looks at whether the ticks are being picked up, so a database or a provider that is down does not get the bot restarted.
+// /readyz fails if the bot cannot answer commands or send alerts: the database, the quote provider, the Slack settings,
+// and the price breach checks, which have to succeed too.
+func createHealthCheckers(db *sql.DB, started time.Time) (*health.Checker, *health.Checker) {
+	checkBreaches := !currentSettings().DisablePriceBreachChecking
+
+	liveness := health.CreateChecker()
+	if checkBreaches {
+		// The interval can be changed by a reload, so the age is worked out on every check
+		liveness.Add("breachTicker", func() (string, error) {
+			return health.Recent("breach tick", lastBreachTick,
+				maxBreachCheckAge(currentSettings().QuoteCheckInterval), started, time.Now)()
+		})
+	}
+
+	readiness := health.CreateChecker()
+	if checkBreaches {
+		readiness.Add("breachCheck", func() (string, error) {
+			return health.Recent("breach check", theAlertManager.LastBreachCheck,
+				maxBreachCheckAge(currentSettings().QuoteCheckInterval), started, time.Now)()
+		})
+	}
+	readiness.Add("database", health.Database(db, databaseCheckTimeout))
+	readiness.AddCached("provider", providerCheckInterval, theBot.Ping)
+	readiness.Add("slack", slackmessaging.CheckNotificationConfig)
+
+	return liveness, readiness
+}
+
+// maxBreachCheckAge - the checker has missed a few ticks in a row. Every tick checks something, even while the exchange is closed.
+func maxBreachCheckAge(intervalSeconds int) time.Duration {
+	return 3*time.Duration(intervalSeconds)*time.Second + time.Minute
+}
+
+// breachTickAt - when the price breach checker last picked up a tick, in Unix nanoseconds. Accessed atomically.
+var breachTickAt int64
+
+// breachTicked - the price breach checker picked up a tick, whether or not the check that follows succeeds
+func breachTicked(at time.Time) {
+	atomic.StoreInt64(&breachTickAt, at.UnixNano())
+}
+
+// lastBreachTick - when the price breach checker last picked up a tick, or the zero time if it never has
+func lastBreachTick() time.Time {
+	if at := atomic.LoadInt64(&breachTickAt); at != 0 {
+		return time.Unix(0, at)
+	}
+	return time.Time{}
+}
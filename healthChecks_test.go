@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/magmasystems/SlackStockSlashCommand/alerts"
+	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
+)
+
+func TestCreateHealthCheckers_BreachCheck(t *testing.T) {
+	oldSettings, oldAlertManager := appSettings, theAlertManager
+	defer func() {
+		appSettings, theAlertManager = oldSettings, oldAlertManager
+		breachTickAt = 0
+	}()
+	appSettings = &config.AppSettings{QuoteCheckInterval: 60}
+	theAlertManager = &alerts.AlertManager{} // none of its breach checks have succeeded
+	started := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		tickedAt time.Time
+		wantLive bool
+	}{
+		{"the ticker is stuck", time.Time{}, false},
+		{"the checks fail, but the ticker is not stuck", time.Now(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breachTickAt = 0
+			if !tt.tickedAt.IsZero() {
+				breachTicked(tt.tickedAt)
+			}
+
+			liveness, _ := createHealthCheckers(nil, started)
+			if _, live := liveness.Run(); live != tt.wantLive {
+				t.Errorf("liveness = %v, want %v", live, tt.wantLive)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Package markethours - knows when the exchanges are open for trading
+package markethours
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
+)
+
+// MarketSession - the trading session that an exchange is in at a point in time
+type MarketSession int
+
+const (
+	// SessionClosed - the exchange is closed (overnight, weekends and holidays)
+	SessionClosed MarketSession = iota
+	// SessionPreMarket - the exchange has not opened yet, but pre-market trading has started
+	SessionPreMarket
+	// SessionRegular - regular trading hours
+	SessionRegular
+	// SessionAfterHours - the exchange has closed for the day
+	SessionAfterHours
+)
+
+func (session MarketSession) String() string {
+	switch session {
+	case SessionPreMarket:
+		return "pre-market"
+	case SessionRegular:
+		return "open"
+	case SessionAfterHours:
+		return "after-hours"
+	default:
+		return "closed"
+	}
+}
+
+// exchangeHours - the default hours of an exchange
+type exchangeHours struct {
+	timeZone      string
+	preMarketOpen string
+	open          string
+	close         string
+}
+
+// The built-in calendars. The holiday list always comes from the appSettings.
+var knownExchanges = map[string]exchangeHours{
+	"NYSE":   {"America/New_York", "04:00", "09:30", "16:00"},
+	"NASDAQ": {"America/New_York", "04:00", "09:30", "16:00"},
+	"LSE":    {"Europe/London", "05:05", "08:00", "16:30"},
+	"XETRA":  {"Europe/Berlin", "08:00", "09:00", "17:30"},
+}
+
+// ExchangeCalendar - the regular hours, time zone and holidays of an exchange
+type ExchangeCalendar struct {
+	Name          string
+	Location      *time.Location
+	preMarketOpen time.Duration
+	open          time.Duration
+	close         time.Duration
+	holidays      map[string]bool
+}
+
+// CreateExchangeCalendar - creates a calendar from the MarketHours section of the appSettings
+func CreateExchangeCalendar(settings config.MarketHoursSettings) (*ExchangeCalendar, error) {
+	name := strings.ToUpper(settings.Exchange)
+	if name == "" {
+		name = "NYSE"
+	}
+
+	hours, ok := knownExchanges[name]
+	if !ok {
+		return nil, fmt.Errorf("the exchange %s has no calendar", settings.Exchange)
+	}
+
+	// Anything in the appSettings overrides the built-in hours
+	if settings.TimeZone != "" {
+		hours.timeZone = settings.TimeZone
+	}
+	if settings.PreMarketOpen != "" {
+		hours.preMarketOpen = settings.PreMarketOpen
+	}
+	if settings.Open != "" {
+		hours.open = settings.Open
+	}
+	if settings.Close != "" {
+		hours.close = settings.Close
+	}
+
+	location, err := time.LoadLocation(hours.timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load the time zone %s: %v", hours.timeZone, err)
+	}
+
+	cal := &ExchangeCalendar{Name: name, Location: location, holidays: make(map[string]bool)}
+
+	if cal.preMarketOpen, err = parseTimeOfDay(hours.preMarketOpen); err != nil {
+		return nil, err
+	}
+	if cal.open, err = parseTimeOfDay(hours.open); err != nil {
+		return nil, err
+	}
+	if cal.close, err = parseTimeOfDay(hours.close); err != nil {
+		return nil, err
+	}
+	if cal.preMarketOpen > cal.open || cal.open >= cal.close {
+		return nil, fmt.Errorf("the hours of the %s exchange are out of order", name)
+	}
+
+	for _, holiday := range settings.Holidays {
+		if _, err = time.Parse("2006-01-02", holiday); err != nil {
+			return nil, fmt.Errorf("the holiday %s is not a YYYY-MM-DD date", holiday)
+		}
+		cal.holidays[holiday] = true
+	}
+
+	return cal, nil
+}
+
+// IsHoliday - is the exchange closed all day because of a weekend or a holiday?
+func (cal *ExchangeCalendar) IsHoliday(t time.Time) bool {
+	local := t.In(cal.Location)
+	if local.Weekday() == time.Saturday || local.Weekday() == time.Sunday {
+		return true
+	}
+	return cal.holidays[local.Format("2006-01-02")]
+}
+
+// Session - returns the trading session that the exchange is in at time t
+func (cal *ExchangeCalendar) Session(t time.Time) MarketSession {
+	if cal.IsHoliday(t) {
+		return SessionClosed
+	}
+
+	local := t.In(cal.Location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, cal.Location)
+	sinceMidnight := local.Sub(midnight)
+
+	switch {
+	case sinceMidnight < cal.preMarketOpen:
+		return SessionClosed
+	case sinceMidnight < cal.open:
+		return SessionPreMarket
+	case sinceMidnight < cal.close:
+		return SessionRegular
+	default:
+		return SessionAfterHours
+	}
+}
+
+// IsOpen - is the exchange in its regular trading session at time t?
+func (cal *ExchangeCalendar) IsOpen(t time.Time) bool {
+	return cal.Session(t) == SessionRegular
+}
+
+// PriceLabel - describes a price that was fetched at time t. Returns an empty string during regular hours.
+func (cal *ExchangeCalendar) PriceLabel(t time.Time) string {
+	switch cal.Session(t) {
+	case SessionRegular:
+		return ""
+	case SessionPreMarket:
+		return "pre-market"
+	default:
+		return "at close"
+	}
+}
+
+// parseTimeOfDay - turns "HH:MM" into the duration since midnight
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("the time %s is not in HH:MM format", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
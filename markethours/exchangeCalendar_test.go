@@ -0,0 +1,64 @@
+package markethours
+
+import (
+	"testing"
+	"time"
+
+	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
+)
+
+func TestExchangeCalendar_Session(t *testing.T) {
+	cal, err := CreateExchangeCalendar(config.MarketHoursSettings{Holidays: []string{"2019-07-04"}})
+	if err != nil {
+		t.Fatalf("CreateExchangeCalendar() error = %v", err)
+	}
+
+	newYork := cal.Location
+	tests := []struct {
+		name      string
+		when      time.Time
+		want      MarketSession
+		wantLabel string
+	}{
+		{"overnight", time.Date(2019, 7, 1, 3, 0, 0, 0, newYork), SessionClosed, "at close"},
+		{"pre-market", time.Date(2019, 7, 1, 8, 0, 0, 0, newYork), SessionPreMarket, "pre-market"},
+		{"at the open", time.Date(2019, 7, 1, 9, 30, 0, 0, newYork), SessionRegular, ""},
+		{"regular hours in UTC", time.Date(2019, 7, 1, 17, 0, 0, 0, time.UTC), SessionRegular, ""},
+		{"at the close", time.Date(2019, 7, 1, 16, 0, 0, 0, newYork), SessionAfterHours, "at close"},
+		{"saturday", time.Date(2019, 7, 6, 12, 0, 0, 0, newYork), SessionClosed, "at close"},
+		{"holiday", time.Date(2019, 7, 4, 12, 0, 0, 0, newYork), SessionClosed, "at close"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cal.Session(tt.when); got != tt.want {
+				t.Errorf("ExchangeCalendar.Session() = %v, want %v", got, tt.want)
+			}
+			if got := cal.PriceLabel(tt.when); got != tt.wantLabel {
+				t.Errorf("ExchangeCalendar.PriceLabel() = %q, want %q", got, tt.wantLabel)
+			}
+		})
+	}
+}
+
+func TestCreateExchangeCalendar(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings config.MarketHoursSettings
+		wantErr  bool
+	}{
+		{"defaults to NYSE", config.MarketHoursSettings{}, false},
+		{"known exchange", config.MarketHoursSettings{Exchange: "lse"}, false},
+		{"unknown exchange", config.MarketHoursSettings{Exchange: "MOON"}, true},
+		{"bad time zone", config.MarketHoursSettings{TimeZone: "Nowhere/Atlantis"}, true},
+		{"bad hours", config.MarketHoursSettings{Open: "9.30"}, true},
+		{"close before open", config.MarketHoursSettings{Open: "16:00", Close: "09:30"}, true},
+		{"bad holiday", config.MarketHoursSettings{Holidays: []string{"July 4th"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CreateExchangeCalendar(tt.settings); (err != nil) != tt.wantErr {
+				t.Errorf("CreateExchangeCalendar() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package markethours
+
+import "time"
+
+// The FX market trades around the clock from Sunday 17:00 to Friday 17:00 in New York
+var fxLocation, _ = time.LoadLocation("America/New_York")
+
+// fxCutoffHour - the hour at which the FX week starts on Sunday and ends on Friday
+const fxCutoffHour = 17
+
+// IsFXMarketOpen - whether FX pairs are trading at a point in time
+func IsFXMarketOpen(t time.Time) bool {
+	location := fxLocation
+	if location == nil {
+		location = time.UTC
+	}
+	local := t.In(location)
+
+	switch local.Weekday() {
+	case time.Saturday:
+		return false
+	case time.Sunday:
+		return local.Hour() >= fxCutoffHour
+	case time.Friday:
+		return local.Hour() < fxCutoffHour
+	default:
+		return true
+	}
+}
@@ -0,0 +1,31 @@
+package markethours
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsFXMarketOpen(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want bool
+	}{
+		{"Wednesday at midnight", time.Date(2019, 7, 3, 0, 0, 0, 0, newYork), true},
+		{"Friday afternoon", time.Date(2019, 7, 5, 16, 59, 0, 0, newYork), true},
+		{"Friday evening", time.Date(2019, 7, 5, 17, 0, 0, 0, newYork), false},
+		{"Saturday", time.Date(2019, 7, 6, 12, 0, 0, 0, newYork), false},
+		{"Sunday afternoon", time.Date(2019, 7, 7, 16, 0, 0, 0, newYork), false},
+		{"Sunday evening", time.Date(2019, 7, 7, 17, 30, 0, 0, newYork), true},
+		{"Sunday 21:30 UTC is 17:30 in New York", time.Date(2019, 7, 7, 21, 30, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsFXMarketOpen(tt.t); got != tt.want {
+				t.Errorf("IsFXMarketOpen() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package metrics
+
+var (
+	cacheLookups  = CreateCounter("stockbot_cache_lookups_total", "The lookups in the caches, by cache and by hit or miss.", "cache", "result")
+	cacheHitRatio = CreateGauge("stockbot_cache_hit_ratio", "The share of the lookups in a cache that were hits, since the bot started.", "cache")
+)
+
+// CacheLookup - counts a lookup in one of the caches, like CacheLookup("symbols", true) for a hit
+func CacheLookup(cache string, hit bool) {
+	if hit {
+		cacheLookups.Inc(cache, "hit")
+	} else {
+		cacheLookups.Inc(cache, "miss")
+	}
+
+	hits, misses := cacheLookups.Value(cache, "hit"), cacheLookups.Value(cache, "miss")
+	cacheHitRatio.Set(hits/(hits+misses), cache)
+}
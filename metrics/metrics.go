@@ -0,0 +1,235 @@
+// Package metrics - counters, gauges and histograms, served in the Prometheus text format
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets - the upper bounds, in seconds, of the latency histograms. They go from a cached
+// database query to a provider call that is about to time out.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// metric - what the registry needs to write a metric
+type metric interface {
+	name() string
+	write(out *strings.Builder)
+}
+
+// family - the name, help and label names that every kind of metric has, and its series by label values
+type family struct {
+	metricName string
+	help       string
+	kind       string
+	labelNames []string
+	lock       sync.Mutex
+}
+
+func (f *family) name() string {
+	return f.metricName
+}
+
+// key - the label values of a series, joined so that they can key a map
+func (f *family) key(labelValues []string) string {
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Sprintf("metrics: %s has the labels %v, but got the values %v", f.metricName, f.labelNames, labelValues))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+// labels - {driver="alphavantage",le="0.5"}, or nothing if there are no labels
+func (f *family) labels(key string, extra ...string) string {
+	var pairs []string
+	if len(f.labelNames) > 0 {
+		for i, value := range strings.Split(key, "\xff") {
+			pairs = append(pairs, fmt.Sprintf(`%s="%s"`, f.labelNames[i], escapeLabelValue(value)))
+		}
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[i], escapeLabelValue(extra[i+1])))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func (f *family) writeHeader(out *strings.Builder) {
+	fmt.Fprintf(out, "# HELP %s %s\n", f.metricName, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(f.help))
+	fmt.Fprintf(out, "# TYPE %s %s\n", f.metricName, f.kind)
+}
+
+// Counter - a count that only goes up, like the number of slash commands
+type Counter struct {
+	family
+	values map[string]float64
+}
+
+// Inc - adds one to the series of the label values
+func (counter *Counter) Inc(labelValues ...string) {
+	counter.Add(1, labelValues...)
+}
+
+// Add - adds to the series of the label values. Counters cannot go down, so negative values are ignored.
+func (counter *Counter) Add(value float64, labelValues ...string) {
+	if value < 0 {
+		return
+	}
+	key := counter.key(labelValues)
+	counter.lock.Lock()
+	counter.values[key] += value
+	counter.lock.Unlock()
+}
+
+// Value - the count of the series of the label values
+func (counter *Counter) Value(labelValues ...string) float64 {
+	key := counter.key(labelValues)
+	counter.lock.Lock()
+	defer counter.lock.Unlock()
+	return counter.values[key]
+}
+
+func (counter *Counter) write(out *strings.Builder) {
+	counter.lock.Lock()
+	defer counter.lock.Unlock()
+
+	counter.writeHeader(out)
+	if len(counter.labelNames) == 0 && len(counter.values) == 0 {
+		fmt.Fprintf(out, "%s 0\n", counter.metricName)
+	}
+	for _, key := range sortedKeys(counter.values) {
+		fmt.Fprintf(out, "%s%s %s\n", counter.metricName, counter.labels(key), formatValue(counter.values[key]))
+	}
+}
+
+// Gauge - a value that goes up and down, like the time of the last breach check
+type Gauge struct {
+	family
+	values map[string]float64
+}
+
+// Set - sets the series of the label values
+func (gauge *Gauge) Set(value float64, labelValues ...string) {
+	key := gauge.key(labelValues)
+	gauge.lock.Lock()
+	gauge.values[key] = value
+	gauge.lock.Unlock()
+}
+
+// SetToTime - sets the series to the time, in seconds since 1970
+func (gauge *Gauge) SetToTime(at time.Time, labelValues ...string) {
+	gauge.Set(float64(at.UnixNano())/1e9, labelValues...)
+}
+
+// Value - the value of the series of the label values
+func (gauge *Gauge) Value(labelValues ...string) float64 {
+	key := gauge.key(labelValues)
+	gauge.lock.Lock()
+	defer gauge.lock.Unlock()
+	return gauge.values[key]
+}
+
+func (gauge *Gauge) write(out *strings.Builder) {
+	gauge.lock.Lock()
+	defer gauge.lock.Unlock()
+
+	gauge.writeHeader(out)
+	if len(gauge.labelNames) == 0 && len(gauge.values) == 0 {
+		fmt.Fprintf(out, "%s 0\n", gauge.metricName)
+	}
+	for _, key := range sortedKeys(gauge.values) {
+		fmt.Fprintf(out, "%s%s %s\n", gauge.metricName, gauge.labels(key), formatValue(gauge.values[key]))
+	}
+}
+
+// Histogram - counts observations, like latencies, in buckets
+type Histogram struct {
+	family
+	buckets []float64
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	counts []uint64 // one per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// Observe - adds an observation to the series of the label values
+func (histogram *Histogram) Observe(value float64, labelValues ...string) {
+	key := histogram.key(labelValues)
+	histogram.lock.Lock()
+	defer histogram.lock.Unlock()
+
+	series, ok := histogram.series[key]
+	if !ok {
+		series = &histogramSeries{counts: make([]uint64, len(histogram.buckets))}
+		histogram.series[key] = series
+	}
+	if i := sort.SearchFloat64s(histogram.buckets, value); i < len(histogram.buckets) {
+		series.counts[i]++
+	}
+	series.count++
+	series.sum += value
+}
+
+// ObserveSince - observes the seconds since the start, like defer latency.ObserveSince(time.Now(), "alphavantage")
+func (histogram *Histogram) ObserveSince(start time.Time, labelValues ...string) {
+	histogram.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+// Count - the number of observations of the series of the label values
+func (histogram *Histogram) Count(labelValues ...string) uint64 {
+	key := histogram.key(labelValues)
+	histogram.lock.Lock()
+	defer histogram.lock.Unlock()
+	if series, ok := histogram.series[key]; ok {
+		return series.count
+	}
+	return 0
+}
+
+func (histogram *Histogram) write(out *strings.Builder) {
+	histogram.lock.Lock()
+	defer histogram.lock.Unlock()
+
+	histogram.writeHeader(out)
+	keys := make([]string, 0, len(histogram.series))
+	for key := range histogram.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		series := histogram.series[key]
+		var cumulative uint64
+		for i, bound := range histogram.buckets {
+			cumulative += series.counts[i]
+			fmt.Fprintf(out, "%s_bucket%s %d\n", histogram.metricName, histogram.labels(key, "le", formatValue(bound)), cumulative)
+		}
+		fmt.Fprintf(out, "%s_bucket%s %d\n", histogram.metricName, histogram.labels(key, "le", "+Inf"), series.count)
+		fmt.Fprintf(out, "%s_sum%s %s\n", histogram.metricName, histogram.labels(key), formatValue(series.sum))
+		fmt.Fprintf(out, "%s_count%s %d\n", histogram.metricName, histogram.labels(key), series.count)
+	}
+}
+
+func sortedKeys(values map[string]float64) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_Text(t *testing.T) {
+	registry := CreateRegistry()
+
+	commands := registry.Counter("stockbot_slash_commands_total", "The slash commands, by command.", "command")
+	commands.Inc("/quote")
+	commands.Inc("/quote")
+	commands.Add(3, `/quote-"alert"`)
+
+	registry.Counter("stockbot_breach_checks_total", "The price breach checks.")
+	registry.Gauge("stockbot_last_breach_check_timestamp_seconds", "When the prices were last checked.").Set(1569931200)
+
+	latency := registry.Histogram("stockbot_provider_request_duration_seconds", "The provider calls.", []float64{0.1, 1}, "driver")
+	latency.Observe(0.05, "alphavantage")
+	latency.Observe(0.5, "alphavantage")
+	latency.Observe(3, "alphavantage")
+
+	want := `# HELP stockbot_slash_commands_total The slash commands, by command.
+# TYPE stockbot_slash_commands_total counter
+stockbot_slash_commands_total{command="/quote"} 2
+stockbot_slash_commands_total{command="/quote-\"alert\""} 3
+# HELP stockbot_breach_checks_total The price breach checks.
+# TYPE stockbot_breach_checks_total counter
+stockbot_breach_checks_total 0
+# HELP stockbot_last_breach_check_timestamp_seconds When the prices were last checked.
+# TYPE stockbot_last_breach_check_timestamp_seconds gauge
+stockbot_last_breach_check_timestamp_seconds 1.5699312e+09
+# HELP stockbot_provider_request_duration_seconds The provider calls.
+# TYPE stockbot_provider_request_duration_seconds histogram
+stockbot_provider_request_duration_seconds_bucket{driver="alphavantage",le="0.1"} 1
+stockbot_provider_request_duration_seconds_bucket{driver="alphavantage",le="1"} 2
+stockbot_provider_request_duration_seconds_bucket{driver="alphavantage",le="+Inf"} 3
+stockbot_provider_request_duration_seconds_sum{driver="alphavantage"} 3.55
+stockbot_provider_request_duration_seconds_count{driver="alphavantage"} 3
+`
+	if got := registry.Text(); got != want {
+		t.Errorf("Registry.Text() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	registry := CreateRegistry()
+	registry.Counter("stockbot_notifications_total", "The notifications.", "result").Inc("sent")
+
+	recorder := httptest.NewRecorder()
+	registry.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("the Content-Type is %s", contentType)
+	}
+	if !strings.Contains(recorder.Body.String(), `stockbot_notifications_total{result="sent"} 1`) {
+		t.Errorf("the body is %s", recorder.Body.String())
+	}
+}
+
+func TestRegistry_DuplicateName(t *testing.T) {
+	registry := CreateRegistry()
+	registry.Counter("stockbot_x_total", "x")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering the same name twice did not panic")
+		}
+	}()
+	registry.Gauge("stockbot_x_total", "x")
+}
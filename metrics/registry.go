@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Registry - the metrics that are served together
+type Registry struct {
+	lock    sync.Mutex
+	metrics []metric
+	byName  map[string]metric
+}
+
+// DefaultRegistry - the registry that the metrics of the bot are created in, and that /metrics serves
+var DefaultRegistry = CreateRegistry()
+
+// CreateRegistry - creates an empty registry
+func CreateRegistry() *Registry {
+	return &Registry{byName: make(map[string]metric)}
+}
+
+// Counter - creates a counter in the registry. The names must be unique, so a second one panics.
+func (registry *Registry) Counter(name string, help string, labelNames ...string) *Counter {
+	counter := &Counter{family: family{metricName: name, help: help, kind: "counter", labelNames: labelNames}, values: make(map[string]float64)}
+	registry.register(counter)
+	return counter
+}
+
+// Gauge - creates a gauge in the registry
+func (registry *Registry) Gauge(name string, help string, labelNames ...string) *Gauge {
+	gauge := &Gauge{family: family{metricName: name, help: help, kind: "gauge", labelNames: labelNames}, values: make(map[string]float64)}
+	registry.register(gauge)
+	return gauge
+}
+
+// Histogram - creates a histogram in the registry. Nil buckets means the DefaultBuckets.
+func (registry *Registry) Histogram(name string, help string, buckets []float64, labelNames ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	histogram := &Histogram{
+		family:  family{metricName: name, help: help, kind: "histogram", labelNames: labelNames},
+		buckets: buckets,
+		series:  make(map[string]*histogramSeries),
+	}
+	registry.register(histogram)
+	return histogram
+}
+
+func (registry *Registry) register(m metric) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	if _, exists := registry.byName[m.name()]; exists {
+		panic(fmt.Sprintf("metrics: %s is already registered", m.name()))
+	}
+	registry.byName[m.name()] = m
+	registry.metrics = append(registry.metrics, m)
+}
+
+// Text - every metric in the Prometheus text format, in the order that they were created
+func (registry *Registry) Text() string {
+	registry.lock.Lock()
+	metrics := append([]metric(nil), registry.metrics...)
+	registry.lock.Unlock()
+
+	var out strings.Builder
+	for _, m := range metrics {
+		m.write(&out)
+	}
+	return out.String()
+}
+
+// ServeHTTP - answers a scrape
+func (registry *Registry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(registry.Text()))
+}
+
+// CreateCounter - creates a counter in the DefaultRegistry
+func CreateCounter(name string, help string, labelNames ...string) *Counter {
+	return DefaultRegistry.Counter(name, help, labelNames...)
+}
+
+// CreateGauge - creates a gauge in the DefaultRegistry
+func CreateGauge(name string, help string, labelNames ...string) *Gauge {
+	return DefaultRegistry.Gauge(name, help, labelNames...)
+}
+
+// CreateHistogram - creates a histogram in the DefaultRegistry
+func CreateHistogram(name string, help string, buckets []float64, labelNames ...string) *Histogram {
+	return DefaultRegistry.Histogram(name, help, buckets, labelNames...)
+}
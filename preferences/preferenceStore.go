@@ -0,0 +1,100 @@
+package preferences
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/magmasystems/SlackStockSlashCommand/framework/logging"
+	"github.com/magmasystems/SlackStockSlashCommand/metrics"
+)
+
+var logger = logging.For("preferences")
+
+// PreferenceStoreOps - the operations that the PreferenceStore can perform
+type PreferenceStoreOps interface {
+	Get(teamID string, userID string) Preferences
+	Save(teamID string, userID string, prefs Preferences) error
+	Delete(teamID string, userID string) error
+}
+
+// PreferenceStore - keeps the preferences in the database, with a cache in front of it,
+// since every quote and every notification needs the preferences of its user
+type PreferenceStore struct {
+	PreferenceStoreOps
+	db    *sql.DB
+	mutex sync.RWMutex
+	cache map[string]Preferences
+}
+
+// CreatePreferenceStore - creates a store on top of an open database
+func CreatePreferenceStore(db *sql.DB) *PreferenceStore {
+	return &PreferenceStore{db: db, cache: make(map[string]Preferences)}
+}
+
+func cacheKey(teamID string, userID string) string {
+	return teamID + "/" + userID
+}
+
+// Get - the preferences of a user. Users who have no settings, or whose settings cannot be read, get the defaults.
+func (store *PreferenceStore) Get(teamID string, userID string) Preferences {
+	key := cacheKey(teamID, userID)
+
+	store.mutex.RLock()
+	prefs, ok := store.cache[key]
+	store.mutex.RUnlock()
+	metrics.CacheLookup("preferences", ok)
+	if ok {
+		return prefs
+	}
+
+	sqlStatement := `SELECT decimals, currency, timezone, defaultchannel, delivery, eventreminders
+	FROM slackstockbot.userpreference
+	WHERE teamid = $1 AND slackuser = $2`
+
+	prefs = Default()
+	row := store.db.QueryRow(sqlStatement, teamID, userID)
+	switch err := row.Scan(&prefs.Decimals, &prefs.Currency, &prefs.TimeZone, &prefs.DefaultChannel, &prefs.Delivery, &prefs.EventReminders); err {
+	case nil, sql.ErrNoRows:
+	default:
+		logger.Error("cannot get the preferences", "team", teamID, "user", userID, "err", err)
+		return Default()
+	}
+
+	store.mutex.Lock()
+	store.cache[key] = prefs
+	store.mutex.Unlock()
+
+	return prefs
+}
+
+// Save - stores the preferences of a user
+func (store *PreferenceStore) Save(teamID string, userID string, prefs Preferences) error {
+	sqlStatement := `
+INSERT INTO slackstockbot.userpreference (teamid, slackuser, decimals, currency, timezone, defaultchannel, delivery, eventreminders)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (teamid, slackuser) DO UPDATE SET decimals = $3, currency = $4, timezone = $5, defaultchannel = $6, delivery = $7, eventreminders = $8`
+
+	_, err := store.db.Exec(sqlStatement, teamID, userID, prefs.Decimals, prefs.Currency, prefs.TimeZone, prefs.DefaultChannel, prefs.Delivery, prefs.EventReminders)
+	if err != nil {
+		logger.Error("cannot save the preferences", "team", teamID, "user", userID, "err", err)
+		return err
+	}
+
+	store.mutex.Lock()
+	store.cache[cacheKey(teamID, userID)] = prefs
+	store.mutex.Unlock()
+	return nil
+}
+
+// Delete - puts a user back on the defaults
+func (store *PreferenceStore) Delete(teamID string, userID string) error {
+	store.mutex.Lock()
+	delete(store.cache, cacheKey(teamID, userID))
+	store.mutex.Unlock()
+
+	_, err := store.db.Exec(`DELETE FROM slackstockbot.userpreference WHERE teamid = $1 AND slackuser = $2`, teamID, userID)
+	if err != nil {
+		logger.Error("cannot delete the preferences", "team", teamID, "user", userID, "err", err)
+	}
+	return err
+}
@@ -0,0 +1,195 @@
+// Package preferences - the way that each Slack user wants to see prices and receive alerts
+package preferences
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The ways that a price alert can be delivered
+const (
+	DeliveryChannel = "channel" // to the channel on the alert, or as a DM if the alert has no channel
+	DeliveryDM      = "dm"      // always as a DM, even if the alert has a channel
+)
+
+// The most decimal places that a price can be shown with
+const maxDecimals = 6
+
+var (
+	currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+	channelPattern  = regexp.MustCompile(`^#[a-z0-9][a-z0-9_\-]*$`)
+)
+
+// Preferences - the settings of a single user
+type Preferences struct {
+	Decimals       int    // the number of decimal places in a price
+	Currency       string // the ISO code of the currency that prices are converted into. Empty means the currency that the symbol is listed in.
+	TimeZone       string // the IANA name of the time zone for timestamps. Empty means the server's time zone.
+	DefaultChannel string // the channel of new alerts that do not name one
+	Delivery       string // DeliveryChannel or DeliveryDM
+	EventReminders bool   // a DM the day before earnings, ex-dividend dates and splits of the alerted symbols
+}
+
+// Default - the preferences of a user who has never used /quote-settings
+func Default() Preferences {
+	return Preferences{Decimals: 2, Delivery: DeliveryChannel}
+}
+
+// FormatPrice - a price with the user's number of decimal places
+func (prefs Preferences) FormatPrice(price float64) string {
+	return fmt.Sprintf("%.*f", prefs.Decimals, price)
+}
+
+// Location - the user's time zone, or the server's if the user has not picked one
+func (prefs Preferences) Location() *time.Location {
+	if prefs.TimeZone != "" {
+		if location, err := time.LoadLocation(prefs.TimeZone); err == nil {
+			return location
+		}
+	}
+	return time.Local
+}
+
+// FormatTime - a timestamp in the user's time zone
+func (prefs Preferences) FormatTime(t time.Time) string {
+	return t.In(prefs.Location()).Format("Jan 2 15:04 MST")
+}
+
+// NotificationChannel - where an alert on the given channel should be posted. An empty channel means a DM.
+func (prefs Preferences) NotificationChannel(alertChannel string) string {
+	if prefs.Delivery == DeliveryDM {
+		return ""
+	}
+	return alertChannel
+}
+
+// Describe - the settings as the user would see them in /quote-settings
+func (prefs Preferences) Describe() string {
+	timeZone := prefs.TimeZone
+	if timeZone == "" {
+		timeZone = "server time (" + time.Now().Format("MST") + ")"
+	}
+	currency, conversion := prefs.Currency, "prices are converted into this currency"
+	if currency == "" {
+		currency, conversion = "listing", "prices are shown in the currency that the symbol is listed in"
+	}
+	channel := prefs.DefaultChannel
+	if channel == "" {
+		channel = "none"
+	}
+	delivery := "to the alert's channel"
+	if prefs.Delivery == DeliveryDM {
+		delivery = "as a direct message"
+	}
+	events, reminders := "off", "no reminders"
+	if prefs.EventReminders {
+		events, reminders = "on", "a DM the day before"
+	}
+
+	return fmt.Sprintf("Your settings are:\n"+
+		"\tdecimals=%d - prices look like %s\n"+
+		"\tcurrency=%s - %s\n"+
+		"\ttimezone=%s\n"+
+		"\tchannel=%s - the channel of new alerts that do not name one\n"+
+		"\tdelivery=%s - alerts are sent %s\n"+
+		"\tevents=%s - %s of the earnings, dividends and splits of your alerted stocks\n",
+		prefs.Decimals, prefs.FormatPrice(1234.5), currency, conversion, timeZone, channel, prefs.Delivery, delivery, events, reminders)
+}
+
+// Apply - changes the settings that are named in text, which looks like "decimals=3 timezone=Europe/London".
+// Every problem in the text is reported, and nothing is changed if there are any.
+func (prefs Preferences) Apply(text string) (Preferences, error) {
+	var problems []string
+
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return prefs, errors.New("expected a setting like decimals=3")
+	}
+
+	for _, field := range fields {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			problems = append(problems, fmt.Sprintf("expected a value after %s=", strings.TrimSuffix(parts[0], "=")))
+			continue
+		}
+		key, value := strings.ToLower(parts[0]), parts[1]
+
+		switch key {
+		case "decimals":
+			decimals, err := strconv.Atoi(value)
+			if err != nil || decimals < 0 || decimals > maxDecimals {
+				problems = append(problems, fmt.Sprintf("decimals must be a number from 0 to %d, not %s", maxDecimals, value))
+				continue
+			}
+			prefs.Decimals = decimals
+
+		case "currency":
+			if strings.EqualFold(value, "listing") {
+				prefs.Currency = ""
+				continue
+			}
+			value = strings.ToUpper(value)
+			if !currencyPattern.MatchString(value) {
+				problems = append(problems, fmt.Sprintf("%s is not a currency code like USD or EUR", value))
+				continue
+			}
+			prefs.Currency = value
+
+		case "timezone", "tz":
+			if strings.EqualFold(value, "server") {
+				prefs.TimeZone = ""
+				continue
+			}
+			if _, err := time.LoadLocation(value); err != nil || strings.EqualFold(value, "local") {
+				problems = append(problems, fmt.Sprintf("%s is not a time zone like America/New_York", value))
+				continue
+			}
+			prefs.TimeZone = value
+
+		case "channel":
+			value = strings.ToLower(value)
+			if value == "none" {
+				prefs.DefaultChannel = ""
+				continue
+			}
+			if !strings.HasPrefix(value, "#") {
+				value = "#" + value
+			}
+			if !channelPattern.MatchString(value) {
+				problems = append(problems, fmt.Sprintf("%s is not a channel name", value))
+				continue
+			}
+			prefs.DefaultChannel = value
+
+		case "delivery":
+			value = strings.ToLower(value)
+			if value != DeliveryChannel && value != DeliveryDM {
+				problems = append(problems, fmt.Sprintf("delivery must be %s or %s, not %s", DeliveryChannel, DeliveryDM, value))
+				continue
+			}
+			prefs.Delivery = value
+
+		case "events":
+			switch strings.ToLower(value) {
+			case "on":
+				prefs.EventReminders = true
+			case "off":
+				prefs.EventReminders = false
+			default:
+				problems = append(problems, fmt.Sprintf("events must be on or off, not %s", value))
+			}
+
+		default:
+			problems = append(problems, fmt.Sprintf("unknown setting %s (expected decimals, currency, timezone, channel, delivery or events)", key))
+		}
+	}
+
+	if len(problems) > 0 {
+		return prefs, errors.New(strings.Join(problems, "; "))
+	}
+	return prefs, nil
+}
@@ -0,0 +1,89 @@
+package preferences
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPreferences_Apply(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    Preferences
+		wantErr string
+	}{
+		{name: "decimals", text: "decimals=4", want: Preferences{Decimals: 4, Delivery: DeliveryChannel}},
+		{name: "everything", text: "decimals=0 currency=eur timezone=Europe/London channel=MyAlerts delivery=DM",
+			want: Preferences{Decimals: 0, Currency: "EUR", TimeZone: "Europe/London", DefaultChannel: "#myalerts", Delivery: DeliveryDM}},
+		{name: "prices are not converted by default", text: "decimals=2", want: Preferences{Decimals: 2, Currency: "", Delivery: DeliveryChannel}},
+		{name: "event reminders", text: "events=ON", want: Preferences{Decimals: 2, Delivery: DeliveryChannel, EventReminders: true}},
+		{name: "tz is short for timezone", text: "tz=America/New_York", want: Preferences{Decimals: 2, TimeZone: "America/New_York", Delivery: DeliveryChannel}},
+
+		{name: "nothing", text: " ", wantErr: "expected a setting like decimals=3"},
+		{name: "no value", text: "decimals", wantErr: "expected a value after decimals="},
+		{name: "too many decimals", text: "decimals=7", wantErr: "decimals must be a number from 0 to 6, not 7"},
+		{name: "bad currency", text: "currency=dollars", wantErr: "DOLLARS is not a currency code like USD or EUR"},
+		{name: "bad time zone", text: "timezone=Mars/Olympus", wantErr: "Mars/Olympus is not a time zone like America/New_York"},
+		{name: "bad delivery", text: "delivery=email", wantErr: "delivery must be channel or dm, not email"},
+		{name: "bad events", text: "events=maybe", wantErr: "events must be on or off, not maybe"},
+		{name: "every problem is listed", text: "decimals=x color=red", wantErr: "decimals must be a number from 0 to 6, not x; unknown setting color (expected decimals, currency, timezone, channel, delivery or events)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Default().Apply(tt.text)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Preferences.Apply() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Preferences.Apply() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Preferences.Apply() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreferences_Apply_ClearsSettings(t *testing.T) {
+	prefs := Preferences{Decimals: 2, Currency: "USD", TimeZone: "Europe/London", DefaultChannel: "#alerts", Delivery: DeliveryDM}
+
+	got, err := prefs.Apply("timezone=server channel=none currency=listing")
+	if err != nil {
+		t.Fatalf("Preferences.Apply() error = %v", err)
+	}
+	if got.TimeZone != "" || got.DefaultChannel != "" || got.Currency != "" {
+		t.Errorf("Preferences.Apply() = %+v, want the time zone, channel and currency cleared", got)
+	}
+}
+
+func TestPreferences_Formatting(t *testing.T) {
+	at := time.Date(2019, 7, 1, 20, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		prefs       Preferences
+		wantPrice   string
+		wantTime    string
+		wantChannel string
+	}{
+		{"defaults", Preferences{Decimals: 2, Delivery: DeliveryChannel, TimeZone: "UTC"}, "133.46", "Jul 1 20:30 UTC", "#alerts"},
+		{"no decimals", Preferences{Decimals: 0, Delivery: DeliveryChannel, TimeZone: "UTC"}, "133", "Jul 1 20:30 UTC", "#alerts"},
+		{"four decimals in New York by DM", Preferences{Decimals: 4, Delivery: DeliveryDM, TimeZone: "America/New_York"}, "133.4567", "Jul 1 16:30 EDT", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.prefs.FormatPrice(133.4567); got != tt.wantPrice {
+				t.Errorf("Preferences.FormatPrice() = %v, want %v", got, tt.wantPrice)
+			}
+			if got := tt.prefs.FormatTime(at); got != tt.wantTime {
+				t.Errorf("Preferences.FormatTime() = %v, want %v", got, tt.wantTime)
+			}
+			if got := tt.prefs.NotificationChannel("#alerts"); got != tt.wantChannel {
+				t.Errorf("Preferences.NotificationChannel() = %v, want %v", got, tt.wantChannel)
+			}
+		})
+	}
+}
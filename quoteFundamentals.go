@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/magmasystems/SlackStockSlashCommand/commands"
+	"github.com/magmasystems/SlackStockSlashCommand/preferences"
+	"github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+	"github.com/magmasystems/SlackStockSlashCommand/slackmessaging"
+	"github.com/nlopes/slack"
+)
+
+// handleQuoteInfo - shows the fundamentals of a stock
+func handleQuoteInfo(request *commands.Request) {
+	symbol := strings.ToUpper(strings.TrimSpace(request.Args))
+	if symbol == "" || strings.ContainsAny(symbol, " ,") {
+		slackmessaging.WriteResponse(request.Writer, "Type one symbol, like `/quote-info MSFT`")
+		return
+	}
+	prefs := userPreferences(request.SlashCommand.TeamID, request.SlashCommand.UserID)
+
+	answerSlowly(request, fmt.Sprintf("Getting the fundamentals of %s...", symbol), func() slack.Message {
+		fundamentals, err := theBot.Fundamentals(symbol)
+		if err != nil {
+			logger.Warn("cannot get the fundamentals", "symbol", symbol, "err", err)
+			return textMessage(fmt.Sprintf("Sorry, %s", err.Error()))
+		}
+		format := fundamentalsFormat(fundamentals, prefs)
+		return format.ToBlock()
+	})
+}
+
+// fundamentalsFormat - lays the fundamentals out as fields. The numbers that the provider does not have show as n/a.
+func fundamentalsFormat(fundamentals *quoteproviders.Fundamentals, prefs preferences.Preferences) slackmessaging.SlackMessageFormat {
+	money := func(value float64) string {
+		if value == 0 {
+			return "n/a"
+		}
+		return formatMoney(prefs, value, fundamentals.Currency)
+	}
+	number := func(value float64, format string) string {
+		if value == 0 {
+			return "n/a"
+		}
+		return fmt.Sprintf(format, value)
+	}
+
+	marketCap := "n/a"
+	if fundamentals.MarketCap != 0 {
+		marketCap = strings.TrimSuffix(formatLargeNumber(fundamentals.MarketCap)+" "+fundamentals.Currency, " ")
+	}
+
+	return slackmessaging.SlackMessageFormat{
+		Title: describeSymbol(fundamentals.Symbol, fundamentals.Name),
+		Fields: []slackmessaging.SlackMessageField{
+			{Title: "Market cap", Value: marketCap},
+			{Title: "P/E", Value: number(fundamentals.PERatio, "%.2f")},
+			{Title: "EPS", Value: money(fundamentals.EPS)},
+			{Title: "Dividend yield", Value: number(fundamentals.DividendYield*100, "%.2f%%")},
+			{Title: "52-week high", Value: money(fundamentals.High52Week)},
+			{Title: "52-week low", Value: money(fundamentals.Low52Week)},
+		},
+	}
+}
+
+// formatLargeNumber - 1020000000000 is 1.02T
+func formatLargeNumber(value float64) string {
+	for _, unit := range []struct {
+		size   float64
+		suffix string
+	}{{1e12, "T"}, {1e9, "B"}, {1e6, "M"}, {1e3, "K"}} {
+		if value >= unit.size {
+			return fmt.Sprintf("%.2f%s", value/unit.size, unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%.0f", value)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/magmasystems/SlackStockSlashCommand/preferences"
+	"github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+	"github.com/magmasystems/SlackStockSlashCommand/slackmessaging"
+)
+
+func TestFundamentalsFormat(t *testing.T) {
+	fundamentals := &quoteproviders.Fundamentals{
+		Symbol:        "MSFT",
+		Name:          "Microsoft Corporation",
+		Currency:      "USD",
+		MarketCap:     1020000000000,
+		PERatio:       28.1,
+		EPS:           4.75,
+		DividendYield: 0.0135,
+		High52Week:    141.68,
+	}
+
+	got := fundamentalsFormat(fundamentals, preferences.Default())
+	if got.Title != "MSFT (Microsoft Corporation)" {
+		t.Errorf("fundamentalsFormat() title = %v", got.Title)
+	}
+	want := []slackmessaging.SlackMessageField{
+		{Title: "Market cap", Value: "1.02T USD"},
+		{Title: "P/E", Value: "28.10"},
+		{Title: "EPS", Value: "4.75"},
+		{Title: "Dividend yield", Value: "1.35%"},
+		{Title: "52-week high", Value: "141.68"},
+		{Title: "52-week low", Value: "n/a"},
+	}
+	if !reflect.DeepEqual(got.Fields, want) {
+		t.Errorf("fundamentalsFormat() fields = %v, want %v", got.Fields, want)
+	}
+}
+
+func TestFormatLargeNumber(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{1020000000000, "1.02T"},
+		{45300000000, "45.30B"},
+		{12500000, "12.50M"},
+		{999, "999"},
+	}
+	for _, tt := range tests {
+		if got := formatLargeNumber(tt.value); got != tt.want {
+			t.Errorf("formatLargeNumber(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
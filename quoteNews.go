@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/magmasystems/SlackStockSlashCommand/alerts"
+	"github.com/magmasystems/SlackStockSlashCommand/commands"
+	"github.com/magmasystems/SlackStockSlashCommand/preferences"
+	"github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+	"github.com/magmasystems/SlackStockSlashCommand/slackmessaging"
+	"github.com/nlopes/slack"
+)
+
+// How far back /quote-news looks, and how many headlines it shows
+const (
+	newsLookbackDays = 3
+	maxHeadlines     = 10
+)
+
+// The news alerts are checked every hour unless the appSettings say otherwise
+const defaultNewsCheckInterval = 60
+
+// handleQuoteNews - shows the latest headlines about a stock
+func handleQuoteNews(request *commands.Request) {
+	symbol := strings.ToUpper(strings.TrimSpace(request.Args))
+	if symbol == "" || strings.ContainsAny(symbol, " ,") {
+		slackmessaging.WriteResponse(request.Writer, "Type one symbol, like `/quote-news MSFT`")
+		return
+	}
+	prefs := userPreferences(request.SlashCommand.TeamID, request.SlashCommand.UserID)
+
+	answerSlowly(request, fmt.Sprintf("Getting the news about %s...", symbol), func() slack.Message {
+		items, err := theBot.FetchNews(symbol, time.Now().AddDate(0, 0, -newsLookbackDays))
+		if err != nil {
+			logger.Warn("cannot get the news", "symbol", symbol, "err", err)
+			return textMessage(fmt.Sprintf("Sorry, %s", err.Error()))
+		}
+		if len(items) == 0 {
+			return textMessage(fmt.Sprintf("There is no news about %s in the last %d days", symbol, newsLookbackDays))
+		}
+		return textMessage(formatHeadlines(items, prefs, maxHeadlines))
+	})
+}
+
+// formatHeadlines - puts each headline on its own line as a link, followed by its source and time. The newest come first.
+func formatHeadlines(items []quoteproviders.NewsItem, prefs preferences.Preferences, limit int) string {
+	outputText := ""
+	for i, item := range items {
+		if i == limit {
+			outputText += fmt.Sprintf("...and %d more\n", len(items)-limit)
+			break
+		}
+
+		headline := item.Title
+		if item.URL != "" {
+			headline = fmt.Sprintf("<%s|%s>", item.URL, item.Title)
+		}
+		outputText += fmt.Sprintf("%s - %s, %s\n", headline, item.Source, prefs.FormatTime(item.PublishedAt))
+	}
+	return outputText
+}
+
+// createNewsAlert - "/quote-news alert MSFT keyword=antitrust"
+func createNewsAlert(request *commands.Request) {
+	outputText, err := theAlertManager.CreateNewsAlert(request.SlashCommand.TeamID, request.SlashCommand.UserID, request.Args)
+	if err != nil {
+		outputText = fmt.Sprintf("Sorry, %s", err.Error())
+	}
+	slackmessaging.WriteResponse(request.Writer, outputText)
+}
+
+// listNewsAlerts - "/quote-news alerts"
+func listNewsAlerts(request *commands.Request) {
+	slackmessaging.WriteResponse(request.Writer, theAlertManager.ListNewsAlerts(request.SlashCommand.TeamID, request.SlashCommand.UserID))
+}
+
+// deleteNewsAlert - "/quote-news delete 12"
+func deleteNewsAlert(request *commands.Request) {
+	slackmessaging.WriteResponse(request.Writer, theAlertManager.DeleteNewsAlert(request.SlashCommand.TeamID, request.SlashCommand.UserID, request.Args))
+}
+
+// newsCheckInterval - the time between the checks of the news alerts. Zero means that they are not checked.
+func newsCheckInterval(minutes int) time.Duration {
+	if minutes < 0 {
+		return 0
+	}
+	if minutes == 0 {
+		minutes = defaultNewsCheckInterval
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// checkNewsAlerts - sends a notification for every news alert that has new articles
+func checkNewsAlerts(now time.Time) {
+	logger.Debug("checking the news alerts")
+	theAlertManager.CheckForNewsAlerts(theBot, now, func(notification alerts.NewsNotification) {
+		prefs := userPreferences(notification.TeamID, notification.SlackUserName)
+		format := slackmessaging.SlackMessageFormat{
+			Color:   "#439FE0",
+			Title:   "News Alert",
+			Text:    notification.Reason + "\n" + formatHeadlines(notification.Items, prefs, maxHeadlines),
+			UseTime: true,
+		}
+		deliverNotification(notification.TeamID, notification.SlackUserName, notification.Channel, format)
+	})
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/magmasystems/SlackStockSlashCommand/preferences"
+	"github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+func TestFormatHeadlines(t *testing.T) {
+	published := time.Date(2019, 10, 1, 14, 30, 0, 0, time.UTC)
+	items := []quoteproviders.NewsItem{
+		{Title: "Microsoft beats estimates", Source: "Reuters", URL: "https://example.com/1", PublishedAt: published},
+		{Title: "No link", Source: "Wire", PublishedAt: published},
+		{Title: "Third", Source: "Wire", PublishedAt: published},
+	}
+	prefs := preferences.Default()
+	at := prefs.FormatTime(published)
+
+	want := "<https://example.com/1|Microsoft beats estimates> - Reuters, " + at + "\n" +
+		"No link - Wire, " + at + "\n" +
+		"...and 1 more\n"
+	if got := formatHeadlines(items, prefs, 2); got != want {
+		t.Errorf("formatHeadlines() = %q, want %q", got, want)
+	}
+}
+
+func TestNewsCheckInterval(t *testing.T) {
+	tests := []struct {
+		minutes int
+		want    time.Duration
+	}{
+		{0, time.Hour},
+		{15, 15 * time.Minute},
+		{-1, 0},
+	}
+	for _, tt := range tests {
+		if got := newsCheckInterval(tt.minutes); got != tt.want {
+			t.Errorf("newsCheckInterval(%d) = %v, want %v", tt.minutes, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/magmasystems/SlackStockSlashCommand/commands"
+	"github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+	"github.com/magmasystems/SlackStockSlashCommand/slackmessaging"
+	"github.com/nlopes/slack"
+)
+
+// The most matches that /quote-search shows
+const maxSearchResults = 10
+
+// handleQuoteSearch - finds the symbols that match a company name or part of a ticker
+func handleQuoteSearch(request *commands.Request) {
+	keywords := strings.TrimSpace(request.Args)
+	if keywords == "" {
+		slackmessaging.WriteResponse(request.Writer, "Type a company name or part of a symbol, like `/quote-search microsoft`")
+		return
+	}
+
+	answerSlowly(request, fmt.Sprintf("Searching for %s...", keywords), func() slack.Message {
+		matches, err := theBot.SearchSymbols(keywords)
+		if err != nil {
+			logger.Warn("cannot search for symbols", "keywords", keywords, "err", err)
+			return textMessage(fmt.Sprintf("Sorry, %s", err.Error()))
+		}
+		return textMessage(formatSymbolMatches(keywords, matches))
+	})
+}
+
+// formatSymbolMatches - puts each match on its own line, with its name, type, region and currency
+func formatSymbolMatches(keywords string, matches []quoteproviders.SymbolMatch) string {
+	if len(matches) == 0 {
+		return fmt.Sprintf("No symbols match %s", keywords)
+	}
+	if len(matches) > maxSearchResults {
+		matches = matches[:maxSearchResults]
+	}
+
+	outputText := fmt.Sprintf("Symbols that match %s:\n", keywords)
+	for _, match := range matches {
+		var details []string
+		for _, detail := range []string{match.Type, match.Region, match.Currency} {
+			if detail != "" {
+				details = append(details, detail)
+			}
+		}
+
+		outputText += fmt.Sprintf("`%s` %s", match.Symbol, match.Name)
+		if len(details) > 0 {
+			outputText += " - " + strings.Join(details, ", ")
+		}
+		outputText += "\n"
+	}
+	return outputText
+}
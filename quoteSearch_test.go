@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+func TestFormatSymbolMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		matches []quoteproviders.SymbolMatch
+		want    string
+	}{
+		{"no matches", nil, "No symbols match micro"},
+		{
+			"matches",
+			[]quoteproviders.SymbolMatch{
+				{Symbol: "MSFT", Name: "Microsoft Corporation", Type: "Equity", Region: "United States", Currency: "USD"},
+				{Symbol: "MCHP", Name: "Microchip Technology"},
+			},
+			"Symbols that match micro:\n`MSFT` Microsoft Corporation - Equity, United States, USD\n`MCHP` Microchip Technology\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatSymbolMatches("micro", tt.matches); got != tt.want {
+				t.Errorf("formatSymbolMatches() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
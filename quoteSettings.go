@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/magmasystems/SlackStockSlashCommand/commands"
+	"github.com/magmasystems/SlackStockSlashCommand/preferences"
+	"github.com/magmasystems/SlackStockSlashCommand/slackmessaging"
+)
+
+// userPreferences - the preferences of a user, or the defaults if there is no preference store
+func userPreferences(teamID string, userID string) preferences.Preferences {
+	if thePreferenceStore == nil {
+		return preferences.Default()
+	}
+	return thePreferenceStore.Get(teamID, userID)
+}
+
+// handleQuoteSettings - shows the settings of the user, or changes the ones that are named
+func handleQuoteSettings(request *commands.Request) {
+	teamID, userID := request.SlashCommand.TeamID, request.SlashCommand.UserID
+	prefs := userPreferences(teamID, userID)
+
+	if request.Args == "" {
+		slackmessaging.WriteResponse(request.Writer, prefs.Describe())
+		return
+	}
+
+	prefs, err := prefs.Apply(request.Args)
+	if err != nil {
+		slackmessaging.WriteResponse(request.Writer, fmt.Sprintf("Sorry, %s. Type `/quote-settings help` to see some examples.", err.Error()))
+		return
+	}
+
+	if err = thePreferenceStore.Save(teamID, userID, prefs); err != nil {
+		slackmessaging.WriteResponse(request.Writer, "Your settings cannot be saved right now")
+		return
+	}
+	slackmessaging.WriteResponse(request.Writer, prefs.Describe())
+}
+
+// resetQuoteSettings - puts the user back on the default settings
+func resetQuoteSettings(request *commands.Request) {
+	if err := thePreferenceStore.Delete(request.SlashCommand.TeamID, request.SlashCommand.UserID); err != nil {
+		slackmessaging.WriteResponse(request.Writer, "Your settings cannot be reset right now")
+		return
+	}
+	slackmessaging.WriteResponse(request.Writer, preferences.Default().Describe())
+}
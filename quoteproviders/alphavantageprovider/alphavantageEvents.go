@@ -0,0 +1,193 @@
+package alphavantageprovider
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	qp "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+const (
+	earningsURL  = "https://www.alphavantage.co/query?function=EARNINGS_CALENDAR&symbol={symbol}&horizon=3month&apikey={apiKey}"
+	dividendsURL = "https://www.alphavantage.co/query?function=DIVIDENDS&symbol={symbol}&apikey={apiKey}"
+	splitsURL    = "https://www.alphavantage.co/query?function=SPLITS&symbol={symbol}&apikey={apiKey}"
+)
+
+// FetchEvents - gets the coming earnings date, and the declared ex-dividend dates and splits of a company
+func (provider AVQuoteProvider) FetchEvents(symbol string) ([]qp.CorporateEvent, error) {
+	var events []qp.CorporateEvent
+
+	parsers := []struct {
+		url   string
+		parse func(symbol string, payload []byte) ([]qp.CorporateEvent, error)
+	}{
+		{earningsURL, parseEarningsCalendar},
+		{dividendsURL, parseDividends},
+		{splitsURL, parseSplits},
+	}
+	for _, parser := range parsers {
+		payload, err := provider.FetchJSONResponse(provider.PrepareURL(parser.url, symbol))
+		if err != nil {
+			return nil, err
+		}
+		found, err := parser.parse(symbol, payload)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, found...)
+	}
+
+	return events, nil
+}
+
+// parseEarningsCalendar - the earnings calendar is CSV, with the columns symbol, name, reportDate, fiscalDateEnding, estimate and currency
+func parseEarningsCalendar(symbol string, payload []byte) ([]qp.CorporateEvent, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(payload), []byte("{")) {
+		return nil, parseError(payload)
+	}
+
+	rows, err := csv.NewReader(bytes.NewReader(payload)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var events []qp.CorporateEvent
+	for i, row := range rows {
+		if i == 0 || len(row) < 6 {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", row[2])
+		if err != nil {
+			continue
+		}
+		detail := "no EPS estimate"
+		if row[4] != "" {
+			detail = fmt.Sprintf("EPS estimate %s %s", row[4], row[5])
+		}
+		events = append(events, qp.CorporateEvent{Symbol: symbol, Kind: qp.EventEarnings, Date: date, Detail: detail})
+	}
+	return events, nil
+}
+
+// parseDividends - the dividends, with their ex-dividend dates
+func parseDividends(symbol string, payload []byte) ([]qp.CorporateEvent, error) {
+	data := new(dividendData)
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, err
+	}
+	if data.Note != "" || data.ErrorMessage != "" {
+		return nil, errors.New(data.Note + data.ErrorMessage)
+	}
+
+	var events []qp.CorporateEvent
+	for _, dividend := range data.Data {
+		date, err := time.Parse("2006-01-02", dividend.ExDividendDate)
+		if err != nil {
+			continue
+		}
+		events = append(events, qp.CorporateEvent{Symbol: symbol, Kind: qp.EventExDividend, Date: date, Detail: dividend.Amount + " per share"})
+	}
+	return events, nil
+}
+
+// parseSplits - the splits, as events
+func parseSplits(symbol string, payload []byte) ([]qp.CorporateEvent, error) {
+	splits, err := parseSplitRatios(symbol, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []qp.CorporateEvent
+	for _, split := range splits {
+		detail := fmt.Sprintf("%s-for-1 split", strconv.FormatFloat(split.Ratio, 'f', -1, 64))
+		events = append(events, qp.CorporateEvent{Symbol: symbol, Kind: qp.EventSplit, Date: split.Date, Detail: detail})
+	}
+	return events, nil
+}
+
+// parseSplitRatios - the splits, with the number of new shares for each old one
+func parseSplitRatios(symbol string, payload []byte) ([]qp.Split, error) {
+	data := new(splitData)
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, err
+	}
+	if data.Note != "" || data.ErrorMessage != "" {
+		return nil, errors.New(data.Note + data.ErrorMessage)
+	}
+
+	var splits []qp.Split
+	for _, split := range data.Data {
+		date, err := time.Parse("2006-01-02", split.EffectiveDate)
+		if err != nil {
+			continue
+		}
+		ratio, err := strconv.ParseFloat(split.SplitFactor, 64)
+		if err != nil || ratio <= 0 {
+			continue
+		}
+		splits = append(splits, qp.Split{Symbol: symbol, Date: date, Ratio: ratio})
+	}
+	return splits, nil
+}
+
+// FetchSplits - gets the splits of a stock that took effect on or after the date
+func (provider AVQuoteProvider) FetchSplits(symbol string, since time.Time) ([]qp.Split, error) {
+	payload, err := provider.FetchJSONResponse(provider.PrepareURL(splitsURL, symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	splits, err := parseSplitRatios(symbol, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var recent []qp.Split
+	for _, split := range splits {
+		if !split.Date.Before(since) {
+			recent = append(recent, split)
+		}
+	}
+	return recent, nil
+}
+
+// parseError - AlphaVantage answers with JSON instead of CSV when something is wrong
+func parseError(payload []byte) error {
+	data := new(struct {
+		Note         string `json:"Note"`
+		ErrorMessage string `json:"Error Message"`
+		Information  string `json:"Information"`
+	})
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return err
+	}
+	return fmt.Errorf("the earnings calendar is not available: %s%s%s", data.Note, data.ErrorMessage, data.Information)
+}
+
+// dividendData - contains the dividends of a company in AlphaVantage format
+type dividendData struct {
+	Note         string `json:"Note"`
+	ErrorMessage string `json:"Error Message"`
+	Symbol       string `json:"symbol"`
+	Data         []struct {
+		ExDividendDate string `json:"ex_dividend_date"`
+		PaymentDate    string `json:"payment_date"`
+		Amount         string `json:"amount"`
+	} `json:"data"`
+}
+
+// splitData - contains the splits of a company in AlphaVantage format
+type splitData struct {
+	Note         string `json:"Note"`
+	ErrorMessage string `json:"Error Message"`
+	Symbol       string `json:"symbol"`
+	Data         []struct {
+		EffectiveDate string `json:"effective_date"`
+		SplitFactor   string `json:"split_factor"`
+	} `json:"data"`
+}
@@ -0,0 +1,58 @@
+package alphavantageprovider
+
+import (
+	"encoding/json"
+	"strconv"
+
+	qp "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+const fxURL = "https://www.alphavantage.co/query?function=CURRENCY_EXCHANGE_RATE&from_currency={from}&to_currency={to}&apikey={apiKey}"
+
+// AVFXProvider - gets exchange rates from the provider
+type AVFXProvider struct {
+	qp.BaseQuoteProvider
+}
+
+// CreateFXProvider - creates a new exchange rate provider
+func CreateFXProvider(apiKey string) qp.FXProvider {
+	fxProvider := new(AVFXProvider)
+	fxProvider.APIKey = apiKey
+	return fxProvider
+}
+
+// Close - closes the provider
+func (provider AVFXProvider) Close() {
+}
+
+// FetchRate - gets the number of toCurrency that one fromCurrency buys
+func (provider AVFXProvider) FetchRate(fromCurrency string, toCurrency string) float32 {
+	url := provider.PrepareFXURL(fxURL, fromCurrency, toCurrency)
+	payload, err := provider.FetchJSONResponse(url)
+
+	if err == nil {
+		data := new(fxData)
+		err = json.Unmarshal(payload, &data)
+		if err != nil {
+			return 0
+		}
+
+		f, _ := strconv.ParseFloat(data.ExchangeRate.Rate, 32)
+		return float32(f)
+	}
+
+	return 0
+}
+
+// fxData - contains an exchange rate in AlphaVantage format
+type fxData struct {
+	ExchangeRate struct {
+		FromCurrencyCode string `json:"1. From_Currency Code"`
+		FromCurrencyName string `json:"2. From_Currency Name"`
+		ToCurrencyCode   string `json:"3. To_Currency Code"`
+		ToCurrencyName   string `json:"4. To_Currency Name"`
+		Rate             string `json:"5. Exchange Rate"`
+		LastRefreshed    string `json:"6. Last Refreshed"`
+		TimeZone         string `json:"7. Time Zone"`
+	} `json:"Realtime Currency Exchange Rate"`
+}
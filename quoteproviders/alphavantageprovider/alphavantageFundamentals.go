@@ -0,0 +1,77 @@
+package alphavantageprovider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	qp "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+const overviewURL = "https://www.alphavantage.co/query?function=OVERVIEW&symbol={symbol}&apikey={apiKey}"
+
+// FetchFundamentals - gets the key statistics of a company
+func (provider AVQuoteProvider) FetchFundamentals(symbol string) (*qp.Fundamentals, error) {
+	payload, err := provider.FetchJSONResponse(provider.PrepareURL(overviewURL, symbol))
+	if err != nil {
+		return nil, err
+	}
+	return parseOverview(symbol, payload)
+}
+
+// parseOverview - turns the OVERVIEW payload into the fundamentals
+func parseOverview(symbol string, payload []byte) (*qp.Fundamentals, error) {
+	data := new(overviewData)
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, err
+	}
+
+	// AlphaVantage answers with a Note when the API key has used up its calls, and with {} for an unknown symbol
+	if data.Note != "" {
+		return nil, errors.New(data.Note)
+	}
+	if data.ErrorMessage != "" {
+		return nil, errors.New(data.ErrorMessage)
+	}
+	if data.Symbol == "" {
+		return nil, fmt.Errorf("there are no fundamentals for %s", symbol)
+	}
+
+	return &qp.Fundamentals{
+		Symbol:        data.Symbol,
+		Name:          data.Name,
+		Currency:      data.Currency,
+		MarketCap:     parseNumber(data.MarketCapitalization),
+		PERatio:       parseNumber(data.PERatio),
+		EPS:           parseNumber(data.EPS),
+		DividendYield: parseNumber(data.DividendYield),
+		High52Week:    parseNumber(data.High52Week),
+		Low52Week:     parseNumber(data.Low52Week),
+	}, nil
+}
+
+// parseNumber - AlphaVantage sends "None" or "-" for the numbers that it does not have, which become zero
+func parseNumber(text string) float64 {
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
+// overviewData - contains the company overview in AlphaVantage format
+type overviewData struct {
+	Note                 string `json:"Note"`
+	ErrorMessage         string `json:"Error Message"`
+	Symbol               string `json:"Symbol"`
+	Name                 string `json:"Name"`
+	Exchange             string `json:"Exchange"`
+	Currency             string `json:"Currency"`
+	MarketCapitalization string `json:"MarketCapitalization"`
+	PERatio              string `json:"PERatio"`
+	EPS                  string `json:"EPS"`
+	DividendYield        string `json:"DividendYield"`
+	High52Week           string `json:"52WeekHigh"`
+	Low52Week            string `json:"52WeekLow"`
+}
@@ -0,0 +1,64 @@
+package alphavantageprovider
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	qp "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+const newsURL = "https://www.alphavantage.co/query?function=NEWS_SENTIMENT&tickers={symbol}&time_from={since}&sort=LATEST&limit=50&apikey={apiKey}"
+
+// FetchNews - gets the articles about a company that were published on or after the time, newest first
+func (provider AVQuoteProvider) FetchNews(symbol string, since time.Time) ([]qp.NewsItem, error) {
+	url := strings.Replace(provider.PrepareURL(newsURL, symbol), "{since}", since.UTC().Format("20060102T1504"), 1)
+	payload, err := provider.FetchJSONResponse(url)
+	if err != nil {
+		return nil, err
+	}
+	return parseNews(symbol, payload)
+}
+
+// parseNews - turns the NEWS_SENTIMENT payload into the news items
+func parseNews(symbol string, payload []byte) ([]qp.NewsItem, error) {
+	data := new(newsData)
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, err
+	}
+	if data.Note != "" || data.ErrorMessage != "" || data.Information != "" {
+		return nil, errors.New(data.Note + data.ErrorMessage + data.Information)
+	}
+
+	items := make([]qp.NewsItem, 0, len(data.Feed))
+	for _, article := range data.Feed {
+		published, err := time.Parse("20060102T150405", article.TimePublished)
+		if err != nil {
+			continue
+		}
+		items = append(items, qp.NewsItem{
+			Symbol:      symbol,
+			Title:       article.Title,
+			Summary:     article.Summary,
+			Source:      article.Source,
+			URL:         article.URL,
+			PublishedAt: published,
+		})
+	}
+	return items, nil
+}
+
+// newsData - contains the news of a company in AlphaVantage format
+type newsData struct {
+	Note         string `json:"Note"`
+	ErrorMessage string `json:"Error Message"`
+	Information  string `json:"Information"`
+	Feed         []struct {
+		Title         string `json:"title"`
+		URL           string `json:"url"`
+		TimePublished string `json:"time_published"`
+		Summary       string `json:"summary"`
+		Source        string `json:"source"`
+	} `json:"feed"`
+}
@@ -0,0 +1,68 @@
+package alphavantageprovider
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+
+	qp "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+const searchURL = "https://www.alphavantage.co/query?function=SYMBOL_SEARCH&keywords={symbol}&apikey={apiKey}"
+
+// SearchSymbols - finds the symbols whose ticker or company name match the keywords
+func (provider AVQuoteProvider) SearchSymbols(keywords string) ([]qp.SymbolMatch, error) {
+	payload, err := provider.FetchJSONResponse(provider.PrepareURL(searchURL, url.QueryEscape(keywords)))
+	if err != nil {
+		return nil, err
+	}
+	return parseSymbolSearch(payload)
+}
+
+// parseSymbolSearch - turns the SYMBOL_SEARCH payload into the matches
+func parseSymbolSearch(payload []byte) ([]qp.SymbolMatch, error) {
+	data := new(searchData)
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, err
+	}
+
+	// AlphaVantage answers with a Note when the API key has used up its calls
+	if data.Note != "" {
+		return nil, errors.New(data.Note)
+	}
+	if data.ErrorMessage != "" {
+		return nil, errors.New(data.ErrorMessage)
+	}
+
+	matches := make([]qp.SymbolMatch, 0, len(data.BestMatches))
+	for _, match := range data.BestMatches {
+		score, _ := strconv.ParseFloat(match.MatchScore, 64)
+		matches = append(matches, qp.SymbolMatch{
+			Symbol:   match.Symbol,
+			Name:     match.Name,
+			Type:     match.Type,
+			Region:   match.Region,
+			Currency: match.Currency,
+			Score:    score,
+		})
+	}
+	return matches, nil
+}
+
+// searchData - contains the results of a symbol search in AlphaVantage format
+type searchData struct {
+	Note         string `json:"Note"`
+	ErrorMessage string `json:"Error Message"`
+	BestMatches  []struct {
+		Symbol      string `json:"1. symbol"`
+		Name        string `json:"2. name"`
+		Type        string `json:"3. type"`
+		Region      string `json:"4. region"`
+		MarketOpen  string `json:"5. marketOpen"`
+		MarketClose string `json:"6. marketClose"`
+		TimeZone    string `json:"7. timezone"`
+		Currency    string `json:"8. currency"`
+		MatchScore  string `json:"9. matchScore"`
+	} `json:"bestMatches"`
+}
@@ -0,0 +1,55 @@
+package coinbase
+
+import (
+	"encoding/json"
+	"strconv"
+
+	qp "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+// https://developers.coinbase.com/api/v2#prices - the spot price of a coin. No API key is needed.
+const spotURL = "https://api.coinbase.com/v2/prices/{from}-{to}/spot"
+
+// CoinbaseProvider - gets crypto prices from Coinbase. A coin's price is the rate of the pair, like BTC-USD.
+type CoinbaseProvider struct {
+	qp.BaseQuoteProvider
+}
+
+// CreateFXProvider - creates a new provider for crypto pairs
+func CreateFXProvider(apiKey string) qp.FXProvider {
+	provider := new(CoinbaseProvider)
+	provider.APIKey = apiKey
+	return provider
+}
+
+// Close - closes the provider
+func (provider CoinbaseProvider) Close() {
+}
+
+// FetchRate - gets the price of one fromCurrency coin in toCurrency
+func (provider CoinbaseProvider) FetchRate(fromCurrency string, toCurrency string) float32 {
+	url := provider.PrepareFXURL(spotURL, fromCurrency, toCurrency)
+	payload, err := provider.FetchJSONResponse(url)
+
+	if err == nil {
+		data := new(spotData)
+		err = json.Unmarshal(payload, &data)
+		if err != nil {
+			return 0
+		}
+
+		f, _ := strconv.ParseFloat(data.Data.Amount, 32)
+		return float32(f)
+	}
+
+	return 0
+}
+
+// spotData - contains a spot price in Coinbase format
+type spotData struct {
+	Data struct {
+		Base     string `json:"base"`
+		Currency string `json:"currency"`
+		Amount   string `json:"amount"`
+	} `json:"data"`
+}
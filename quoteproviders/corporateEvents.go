@@ -0,0 +1,23 @@
+package quoteproviders
+
+import "time"
+
+// The kinds of corporate events
+const (
+	EventEarnings   = "earnings"
+	EventExDividend = "ex-dividend"
+	EventSplit      = "split"
+)
+
+// CorporateEvent - a date in a company's calendar that can move its stock price
+type CorporateEvent struct {
+	Symbol string
+	Kind   string    // EventEarnings, EventExDividend or EventSplit
+	Date   time.Time // midnight UTC of the day of the event
+	Detail string    // like "EPS estimate 1.21 USD", "0.51 per share" or "2-for-1"
+}
+
+// EventsProvider - implemented by the quote providers that know when companies report earnings, pay dividends and split
+type EventsProvider interface {
+	FetchEvents(symbol string) ([]CorporateEvent, error)
+}
@@ -0,0 +1,51 @@
+package exchangeratesapi
+
+import (
+	"encoding/json"
+
+	qp "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+// https://exchangeratesapi.io - the reference rates of the European Central Bank. No API key is needed.
+const fxURL = "https://api.exchangeratesapi.io/latest?base={from}&symbols={to}"
+
+// ECBFXProvider - gets exchange rates from exchangeratesapi.io
+type ECBFXProvider struct {
+	qp.BaseQuoteProvider
+}
+
+// CreateFXProvider - creates a new exchange rate provider
+func CreateFXProvider(apiKey string) qp.FXProvider {
+	fxProvider := new(ECBFXProvider)
+	fxProvider.APIKey = apiKey
+	return fxProvider
+}
+
+// Close - closes the provider
+func (provider ECBFXProvider) Close() {
+}
+
+// FetchRate - gets the number of toCurrency that one fromCurrency buys
+func (provider ECBFXProvider) FetchRate(fromCurrency string, toCurrency string) float32 {
+	url := provider.PrepareFXURL(fxURL, fromCurrency, toCurrency)
+	payload, err := provider.FetchJSONResponse(url)
+
+	if err == nil {
+		data := new(fxData)
+		err = json.Unmarshal(payload, &data)
+		if err != nil {
+			return 0
+		}
+
+		return float32(data.Rates[toCurrency])
+	}
+
+	return 0
+}
+
+// fxData - contains the exchange rates in exchangeratesapi.io format
+type fxData struct {
+	Base  string             `json:"base"`
+	Date  string             `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+}
@@ -0,0 +1,19 @@
+package quoteproviders
+
+// Fundamentals - the key statistics of a company. A zero means that the provider does not have the number.
+type Fundamentals struct {
+	Symbol        string
+	Name          string
+	Currency      string
+	MarketCap     float64
+	PERatio       float64
+	EPS           float64
+	DividendYield float64 // a fraction, so 0.015 is 1.5%
+	High52Week    float64
+	Low52Week     float64
+}
+
+// FundamentalsProvider - implemented by the quote providers that have the key statistics of a company
+type FundamentalsProvider interface {
+	FetchFundamentals(symbol string) (*Fundamentals, error)
+}
@@ -0,0 +1,20 @@
+package quoteproviders
+
+import "strings"
+
+// FXProvider - all foreign exchange rate providers must implement this interface
+type FXProvider interface {
+	FetchRate(fromCurrency string, toCurrency string) float32
+	SetAPIKey(apiKey string)
+	Close()
+}
+
+// PrepareFXURL - given the provider's Get URL for retrieving an exchange rate, and the two currencies,
+// returns a valid URL which can be used in the REST GET call.
+func (provider BaseQuoteProvider) PrepareFXURL(fxURL string, fromCurrency string, toCurrency string) string {
+	url := strings.Replace(fxURL, "{from}", fromCurrency, 1)
+	url = strings.Replace(url, "{to}", toCurrency, 1)
+	url = strings.Replace(url, "{apiKey}", provider.APIKey, 1)
+
+	return url
+}
@@ -0,0 +1,18 @@
+package quoteproviders
+
+import "time"
+
+// NewsItem - a news article about a symbol
+type NewsItem struct {
+	Symbol      string
+	Title       string
+	Summary     string
+	Source      string
+	URL         string
+	PublishedAt time.Time
+}
+
+// NewsProvider - implemented by the quote providers that have news about companies
+type NewsProvider interface {
+	FetchNews(symbol string, since time.Time) ([]NewsItem, error)
+}
@@ -0,0 +1,58 @@
+package quandlprovider
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	qp "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+const splitsURL = "https://www.quandl.com/api/v3/datasets/WIKI/{symbol}.json?start_date={since}&end_date={today}&api_key={apiKey}"
+
+// FetchSplits - gets the splits of a stock from the "Split Ratio" column, which is 1 on the days without a split
+func (provider QuandlQuoteProvider) FetchSplits(symbol string, since time.Time) ([]qp.Split, error) {
+	url := strings.Replace(provider.PrepareURL(splitsURL, symbol), "{since}", since.Format("2006-01-02"), 1)
+	payload, err := provider.FetchJSONResponse(url)
+	if err != nil {
+		return nil, err
+	}
+	return parseSplits(symbol, payload)
+}
+
+// parseSplits - finds the rows of the dataset whose split ratio is not 1
+func parseSplits(symbol string, payload []byte) ([]qp.Split, error) {
+	data := new(quoteData)
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, err
+	}
+
+	dateColumn, ratioColumn := -1, -1
+	for i, name := range data.Dataset.ColumnNames {
+		switch name {
+		case "Date":
+			dateColumn = i
+		case "Split Ratio":
+			ratioColumn = i
+		}
+	}
+	if dateColumn < 0 || ratioColumn < 0 {
+		return nil, errors.New("the dataset of " + symbol + " has no Split Ratio column")
+	}
+
+	var splits []qp.Split
+	for _, row := range data.Dataset.Data {
+		if len(row) <= dateColumn || len(row) <= ratioColumn {
+			continue
+		}
+		text, _ := row[dateColumn].(string)
+		ratio, _ := row[ratioColumn].(float64)
+		date, err := time.Parse("2006-01-02", text)
+		if err != nil || ratio <= 0 || ratio == 1 {
+			continue
+		}
+		splits = append(splits, qp.Split{Symbol: symbol, Date: date, Ratio: ratio})
+	}
+	return splits, nil
+}
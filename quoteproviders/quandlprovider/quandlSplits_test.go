@@ -0,0 +1,33 @@
+package quandlprovider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSplits(t *testing.T) {
+	payload := []byte(`{"dataset": {
+		"column_names": ["Date", "Open", "High", "Low", "Close", "Volume", "Ex-Dividend", "Split Ratio"],
+		"data": [
+			["2014-06-09", 92.7, 93.88, 91.75, 93.7, 75415807.0, 0.0, 7.0],
+			["2014-06-06", 649.9, 651.26, 644.47, 645.57, 12497800.0, 0.0, 1.0]
+		]
+	}}`)
+
+	splits, err := parseSplits("AAPL", payload)
+	if err != nil {
+		t.Fatalf("parseSplits() error = %v", err)
+	}
+	if len(splits) != 1 {
+		t.Fatalf("parseSplits() = %v, want one split", splits)
+	}
+	if want := time.Date(2014, 6, 9, 0, 0, 0, 0, time.UTC); !splits[0].Date.Equal(want) || splits[0].Ratio != 7 {
+		t.Errorf("parseSplits() = %+v, want a 7-for-1 split on %v", splits[0], want)
+	}
+}
+
+func TestParseSplits_NoSplitColumn(t *testing.T) {
+	if _, err := parseSplits("AAPL", []byte(`{"dataset": {"column_names": ["Date", "Close"], "data": []}}`)); err == nil {
+		t.Errorf("parseSplits() should fail without a Split Ratio column")
+	}
+}
@@ -3,12 +3,42 @@ package quoteproviders
 import (
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
+	neturl "net/url"
 	"strings"
 	"time"
+
+	"github.com/magmasystems/SlackStockSlashCommand/metrics"
+)
+
+var (
+	providerRequestDuration = metrics.CreateHistogram("stockbot_provider_request_duration_seconds",
+		"How long the calls to the quote providers take, by driver.", nil, "driver")
+	providerErrors = metrics.CreateCounter("stockbot_provider_errors_total",
+		"The calls to the quote providers that failed or were refused, by driver.", "driver")
 )
 
+// The drivers that the hosts of the provider APIs belong to
+var driverHosts = map[string]string{
+	"www.alphavantage.co":      "alphavantage",
+	"api.worldtradingdata.com": "worldtradingdata",
+	"www.quandl.com":           "quandl",
+	"api.exchangeratesapi.io":  "exchangeratesapi",
+	"api.coinbase.com":         "coinbase",
+}
+
+// driverOf - the driver that a provider URL belongs to, or its host if it is not one of the drivers
+func driverOf(url string) string {
+	parsed, err := neturl.Parse(url)
+	if err != nil || parsed.Host == "" {
+		return "unknown"
+	}
+	if driver, ok := driverHosts[parsed.Host]; ok {
+		return driver
+	}
+	return parsed.Host
+}
+
 // QuoteProvider - all quote providers must implement this interface
 type QuoteProvider interface {
 	FetchQuote(symbol string) float32
@@ -49,14 +79,25 @@ func (provider BaseQuoteProvider) PrepareURL(quoteURL string, symbol string) str
 
 // FetchJSONResponse - calls the REST API fo fetch a quote and returns the JSON payload
 func (provider BaseQuoteProvider) FetchJSONResponse(url string) ([]byte, error) {
+	driver := driverOf(url)
+	defer providerRequestDuration.ObserveSince(time.Now(), driver)
+
 	resp, err := http.Get(url)
 	if err != nil {
-		log.Fatalln(err)
+		providerErrors.Inc(driver)
+		return nil, err
 	}
+	defer resp.Body.Close()
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Fatalln(err)
+		providerErrors.Inc(driver)
+		return nil, err
+	}
+
+	// The providers put the reason in the body, so it is still returned
+	if resp.StatusCode >= http.StatusBadRequest {
+		providerErrors.Inc(driver)
 	}
 
 	// fmt.Println(string(body))
@@ -0,0 +1,16 @@
+package quoteproviders
+
+import "time"
+
+// Split - a stock split. The Ratio is the number of new shares for each old one, so a 4-for-1 split is 4,
+// and a 1-for-10 reverse split is 0.1. Prices from before the split are divided by the ratio.
+type Split struct {
+	Symbol string
+	Date   time.Time // midnight UTC of the day that the stock started trading at the new price
+	Ratio  float64
+}
+
+// SplitProvider - implemented by the quote providers that know when stocks split
+type SplitProvider interface {
+	FetchSplits(symbol string, since time.Time) ([]Split, error)
+}
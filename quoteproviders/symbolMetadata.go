@@ -0,0 +1,16 @@
+package quoteproviders
+
+// SymbolMetadata - what a provider knows about a symbol besides its price
+type SymbolMetadata struct {
+	Symbol    string
+	Name      string
+	Exchange  string
+	Currency  string
+	AssetType string
+}
+
+// MetadataReporter - implemented by the quote providers whose quotes also carry the company name.
+// The handler is called with the metadata of every symbol that is quoted.
+type MetadataReporter interface {
+	SetMetadataHandler(handler func(SymbolMetadata))
+}
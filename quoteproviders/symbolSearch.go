@@ -0,0 +1,16 @@
+package quoteproviders
+
+// SymbolMatch - a symbol that matches the keywords of a search
+type SymbolMatch struct {
+	Symbol   string
+	Name     string
+	Type     string // Equity, ETF, Mutual Fund, ...
+	Region   string // where the symbol is listed, like United States or XETRA
+	Currency string
+	Score    float64 // how well the provider thinks that the symbol matches, from 0 to 1
+}
+
+// SymbolSearcher - implemented by the quote providers that can find symbols by a company name or part of a ticker
+type SymbolSearcher interface {
+	SearchSymbols(keywords string) ([]SymbolMatch, error)
+}
@@ -15,6 +15,7 @@ const quoteURL = "https://api.worldtradingdata.com/api/v1/stock?symbol={symbol}&
 // WTDQuoteProvider - gets quotes from worldtradingdata.com
 type WTDQuoteProvider struct {
 	qp.BaseQuoteProvider
+	onMetadata func(qp.SymbolMetadata)
 }
 
 // CreateQuoteProvider - creates a new quote provider
@@ -28,6 +29,11 @@ func CreateQuoteProvider(apiKey string) qp.QuoteProvider {
 func (provider WTDQuoteProvider) Close() {
 }
 
+// SetMetadataHandler - every quote comes with the name, exchange and currency of the stock, which are passed to the handler
+func (provider *WTDQuoteProvider) SetMetadataHandler(handler func(qp.SymbolMetadata)) {
+	provider.onMetadata = handler
+}
+
 // FetchQuote - gets a quote
 func (provider WTDQuoteProvider) FetchQuote(symbol string) float32 {
 
@@ -41,8 +47,21 @@ func (provider WTDQuoteProvider) FetchQuote(symbol string) float32 {
 			return 0
 		}
 		fmt.Println(data)
+		if len(data.Data) == 0 {
+			return 0
+		}
+
+		stock := data.Data[0]
+		if provider.onMetadata != nil {
+			provider.onMetadata(qp.SymbolMetadata{
+				Symbol:   symbol,
+				Name:     stock.Name,
+				Exchange: stock.StockExchangeShort,
+				Currency: stock.Currency,
+			})
+		}
 
-		f, _ := strconv.ParseFloat(data.Data[0].Price, 32)
+		f, _ := strconv.ParseFloat(stock.Price, 32)
 		return float32(f)
 	}
 
@@ -56,6 +75,8 @@ type quoteData struct {
 	Data             []struct {
 		Symbol             string `json:"symbol"`
 		Name               string `json:"name"`
+		Currency           string `json:"currency"`
+		StockExchangeShort string `json:"stock_exchange_short"`
 		Price              string `json:"price"`
 		CloseYesterday     string `json:"close_yesterday"`
 		ReturnYtd          string `json:"return_ytd"`
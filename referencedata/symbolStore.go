@@ -0,0 +1,104 @@
+package referencedata
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/magmasystems/SlackStockSlashCommand/framework/logging"
+	"github.com/magmasystems/SlackStockSlashCommand/metrics"
+)
+
+var logger = logging.For("referencedata")
+
+// SymbolInfo - what is known about a symbol besides its price
+type SymbolInfo struct {
+	Symbol    string
+	Name      string
+	Exchange  string
+	Currency  string
+	AssetType string
+	UpdatedAt time.Time
+}
+
+// sameAs - true if the two describe the symbol in the same way, no matter when they were saved
+func (info SymbolInfo) sameAs(other SymbolInfo) bool {
+	info.UpdatedAt, other.UpdatedAt = time.Time{}, time.Time{}
+	return info == other
+}
+
+// SymbolStoreOps - the operations that the SymbolStore can perform
+type SymbolStoreOps interface {
+	Get(symbol string) *SymbolInfo
+	Save(info SymbolInfo) error
+}
+
+// SymbolStore - keeps the reference data of the symbols in the database, with a cache in front of it,
+// since every quote looks up the name of its symbol
+type SymbolStore struct {
+	SymbolStoreOps
+	db    *sql.DB
+	mutex sync.RWMutex
+	cache map[string]*SymbolInfo // a nil entry means that the symbol is not in the database
+}
+
+// CreateSymbolStore - creates a store on top of an open database
+func CreateSymbolStore(db *sql.DB) *SymbolStore {
+	return &SymbolStore{db: db, cache: make(map[string]*SymbolInfo)}
+}
+
+// Get - the reference data of a symbol, or nil if nothing is known about it
+func (store *SymbolStore) Get(symbol string) *SymbolInfo {
+	store.mutex.RLock()
+	info, ok := store.cache[symbol]
+	store.mutex.RUnlock()
+	metrics.CacheLookup("symbols", ok)
+	if ok {
+		return info
+	}
+
+	sqlStatement := `SELECT symbol, name, exchange, currency, assettype, updatedat
+	FROM slackstockbot.symbol
+	WHERE symbol = $1`
+
+	info = new(SymbolInfo)
+	row := store.db.QueryRow(sqlStatement, symbol)
+	switch err := row.Scan(&info.Symbol, &info.Name, &info.Exchange, &info.Currency, &info.AssetType, &info.UpdatedAt); err {
+	case nil:
+	case sql.ErrNoRows:
+		info = nil
+	default:
+		logger.Error("cannot get the symbol", "symbol", symbol, "err", err)
+		return nil
+	}
+
+	store.mutex.Lock()
+	store.cache[symbol] = info
+	store.mutex.Unlock()
+
+	return info
+}
+
+// Save - stores the reference data of a symbol. Nothing is written if it has not changed.
+func (store *SymbolStore) Save(info SymbolInfo) error {
+	if known := store.Get(info.Symbol); known != nil && known.sameAs(info) {
+		return nil
+	}
+	info.UpdatedAt = time.Now()
+
+	sqlStatement := `
+INSERT INTO slackstockbot.symbol (symbol, name, exchange, currency, assettype, updatedat)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (symbol) DO UPDATE SET name = $2, exchange = $3, currency = $4, assettype = $5, updatedat = $6`
+
+	_, err := store.db.Exec(sqlStatement, info.Symbol, info.Name, info.Exchange, info.Currency, info.AssetType, info.UpdatedAt)
+	if err != nil {
+		logger.Error("cannot save the symbol", "symbol", info.Symbol, "err", err)
+		return err
+	}
+
+	store.mutex.Lock()
+	store.cache[info.Symbol] = &info
+	store.mutex.Unlock()
+	return nil
+}
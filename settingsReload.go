@@ -0,0 +1,135 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
+	"github.com/magmasystems/SlackStockSlashCommand/framework/logging"
+	"github.com/magmasystems/SlackStockSlashCommand/slackmessaging"
+)
+
+// How often the appSettings file is checked for changes
+const settingsPollInterval = 10 * time.Second
+
+// The settings that are only used while the bot starts, so a change to one of them needs a restart
+var restartSettings = []string{"port", "transport", "database", "slackSecret", "slackAppToken", "oauth", "marketHours",
+	"disablePriceBreachChecking", "newsCheckInterval", "eventReminderHour"}
+
+var appSettingsLock sync.RWMutex
+
+// currentSettings - the appSettings, which can be replaced by a reload while the bot runs
+func currentSettings() *config.AppSettings {
+	appSettingsLock.RLock()
+	defer appSettingsLock.RUnlock()
+	return appSettings
+}
+
+// watchSettings - reloads the appSettings on a SIGHUP, or when the appSettings file is saved
+func watchSettings() {
+	fileName := config.SettingsFileName(os.Args[1:])
+	lastModified := modifiedAt(fileName)
+
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+
+	theLifecycle.Go("settings watcher", func(stop <-chan struct{}) {
+		defer signal.Stop(hangups)
+		ticker := time.NewTicker(settingsPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-hangups:
+				reloadSettings("SIGHUP")
+			case <-ticker.C:
+				if at := modifiedAt(fileName); !at.Equal(lastModified) {
+					lastModified = at
+					reloadSettings(fileName + " changed")
+				}
+			}
+		}
+	})
+}
+
+// modifiedAt - when the file was last saved. Zero if there is no file.
+func modifiedAt(fileName string) time.Time {
+	if fileName == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// reloadSettings - loads the appSettings again, and applies them if they are valid
+func reloadSettings(reason string) {
+	settings, err := config.Load(os.Args[1:], os.Environ())
+	if err != nil {
+		logger.Warn("the reloaded appSettings are invalid, so the old ones are kept", "reason", reason, "err", err)
+		return
+	}
+
+	old := currentSettings()
+	changes := config.Diff(old, settings)
+	if len(changes) == 0 {
+		logger.Info("reloaded the appSettings, and nothing changed", "reason", reason)
+		return
+	}
+
+	logger.Info("reloaded the appSettings", "reason", reason, "changes", len(changes))
+	for _, change := range changes {
+		logger.Info("a setting changed", "change", change, "needs_restart", needsRestart(change))
+	}
+	applySettings(old, settings)
+}
+
+// applySettings - gives the new appSettings to the parts of the bot that can change while it runs
+func applySettings(old *config.AppSettings, settings *config.AppSettings) {
+	if providersChanged(old, settings) {
+		theBot.Reconfigure(settings)
+	}
+
+	if settings.QuoteCheckInterval != old.QuoteCheckInterval && priceBreachCheckIntervals != nil {
+		// Only the latest interval matters, if the ticker has not picked up the one before it yet
+		select {
+		case <-priceBreachCheckIntervals:
+		default:
+		}
+		priceBreachCheckIntervals <- time.Duration(settings.QuoteCheckInterval) * time.Second
+	}
+
+	slackmessaging.Configure(settings)
+	if err := logging.Configure(settings.Logging); err != nil {
+		logger.Warn("the Logging settings are invalid", "err", err)
+	}
+
+	appSettingsLock.Lock()
+	appSettings = settings
+	appSettingsLock.Unlock()
+}
+
+// providersChanged - whether the quote providers have to be created again
+func providersChanged(old *config.AppSettings, settings *config.AppSettings) bool {
+	return old.Driver != settings.Driver || old.FXDriver != settings.FXDriver ||
+		!reflect.DeepEqual(old.AssetDrivers, settings.AssetDrivers) || !reflect.DeepEqual(old.APIKeys, settings.APIKeys)
+}
+
+// needsRestart - whether a change, like "port: 5000 -> 5001", is to a setting that is only used at the start
+func needsRestart(change string) bool {
+	for _, name := range restartSettings {
+		if strings.HasPrefix(change, name+":") || strings.HasPrefix(change, name+".") {
+			return true
+		}
+	}
+	return false
+}
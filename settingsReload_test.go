@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+
+	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
+)
+
+func TestNeedsRestart(t *testing.T) {
+	tests := []struct {
+		change string
+		want   bool
+	}{
+		{"quoteCheckInterval: 600 -> 60", false},
+		{"driver: quandl -> alphavantage", false},
+		{"port: 5000 -> 5001", true},
+		{"database.password: changed", true},
+		{"marketHours.holidays: [] -> [2019-12-25]", true},
+		{"portfolio: added", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.change, func(t *testing.T) {
+			if got := needsRestart(tt.change); got != tt.want {
+				t.Errorf("needsRestart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvidersChanged(t *testing.T) {
+	old := &config.AppSettings{Driver: "quandl", APIKeys: map[string]string{"quandl": "key"}}
+	tests := []struct {
+		name     string
+		settings *config.AppSettings
+		want     bool
+	}{
+		{"same", &config.AppSettings{Driver: "quandl", APIKeys: map[string]string{"quandl": "key"}, QuoteCheckInterval: 60}, false},
+		{"driver", &config.AppSettings{Driver: "alphavantage", APIKeys: map[string]string{"quandl": "key"}}, true},
+		{"API key", &config.AppSettings{Driver: "quandl", APIKeys: map[string]string{"quandl": "new"}}, true},
+		{"asset driver", &config.AppSettings{Driver: "quandl", APIKeys: map[string]string{"quandl": "key"}, AssetDrivers: map[string]string{"crypto": "coinbase"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := providersChanged(old, tt.settings); got != tt.want {
+				t.Errorf("providersChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
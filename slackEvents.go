@@ -0,0 +1,159 @@
+package main
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/magmasystems/SlackStockSlashCommand/slackmessaging"
+	"github.com/nlopes/slack/slackevents"
+)
+
+// The most symbols that we will quote in one reply
+const maxSymbolsPerReply = 10
+
+var (
+	cashtagPattern     = regexp.MustCompile(`(?:^|[\s(])\$([A-Za-z][A-Za-z0-9.\-]{0,9})`)
+	mentionPattern     = regexp.MustCompile(`<@[A-Z0-9]+(?:\|[^>]*)?>`)
+	mentionWordPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.\-]{0,9}$`)
+	letterPattern      = regexp.MustCompile(`[A-Za-z]`)
+)
+
+// handleEventsRequest - the Events API endpoint
+func handleEventsRequest(w http.ResponseWriter, r *http.Request, signingSecret string) {
+	event, err := slackmessaging.ProcessIncomingEvent(r, w, signingSecret)
+	if err != nil {
+		logger.Warn("the event cannot be processed", "err", err)
+		return
+	}
+	if event.Type != slackevents.CallbackEvent {
+		return
+	}
+
+	// Slack retries an event if we were slow to acknowledge it. We have already answered it.
+	// The retry is only dropped once its signature has been checked, like any other request.
+	if retry := r.Header.Get("X-Slack-Retry-Num"); retry != "" {
+		logger.Debug("dropping a retried event", "retry", retry, "reason", r.Header.Get("X-Slack-Retry-Reason"))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Acknowledge the event right away. The quotes can take longer than the 3 seconds that Slack gives us.
+	w.WriteHeader(http.StatusOK)
+	go dispatchEvent(event)
+}
+
+func dispatchEvent(event slackevents.EventsAPIEvent) {
+	var botUsers []string
+	if callback, ok := event.Data.(*slackevents.EventsAPICallbackEvent); ok {
+		botUsers = callback.AuthedUsers
+	}
+
+	switch ev := event.InnerEvent.Data.(type) {
+	case *slackevents.AppMentionEvent:
+		replyWithQuotes(event.TeamID, ev.User, ev.Channel, threadOf(ev.TimeStamp, ev.ThreadTimeStamp), symbolsFromMention(ev.Text))
+
+	case *slackevents.MessageEvent:
+		// Skip edits, bot messages (including our own replies) and messages that mention us, which come as an app_mention too
+		if !currentSettings().ExpandCashtags || ev.SubType != "" || ev.BotID != "" || mentionsAny(ev.Text, botUsers) {
+			return
+		}
+		replyWithQuotes(event.TeamID, ev.User, ev.Channel, threadOf(ev.TimeStamp, ev.ThreadTimeStamp), cashtags(ev.Text))
+
+	case *slackevents.AppUninstalledEvent:
+		forgetWorkspace(event.TeamID, true)
+
+	case *slackevents.TokensRevokedEvent:
+		if len(ev.Tokens.Bot) > 0 {
+			forgetWorkspace(event.TeamID, false)
+		}
+	}
+}
+
+// forgetWorkspace - the bot token of the team no longer works. When the app is uninstalled, the alerts go too.
+func forgetWorkspace(teamID string, deleteAlerts bool) {
+	logger.Info("the bot was removed from the workspace", "team", teamID)
+
+	if theWorkspaceStore != nil {
+		theWorkspaceStore.Delete(teamID)
+	}
+	if deleteAlerts {
+		theAlertManager.DeleteTeamAlerts(teamID)
+	}
+}
+
+// replyWithQuotes - quotes the symbols and posts the prices in the thread, formatted the way the poster likes them
+func replyWithQuotes(teamID string, userID string, channel string, threadTimeStamp string, symbols []string) {
+	if len(symbols) == 0 {
+		return
+	}
+
+	format := slackmessaging.SlackMessageFormat{Text: formatQuotes(theBot.Quote(symbols), userPreferences(teamID, userID), "")}
+	if err := slackmessaging.PostThreadReply(teamID, channel, threadTimeStamp, format); err != nil {
+		logger.Warn("cannot reply in the thread", "team", teamID, "channel", channel, "err", err)
+	}
+}
+
+// threadOf - replies go into the thread that the message is in, or start a new thread on the message
+func threadOf(timeStamp string, threadTimeStamp string) string {
+	if threadTimeStamp != "" {
+		return threadTimeStamp
+	}
+	return timeStamp
+}
+
+// cashtags - finds all of the $TICKER symbols in a message
+func cashtags(text string) []string {
+	var symbols []string
+	for _, match := range cashtagPattern.FindAllStringSubmatch(text, -1) {
+		symbols = appendSymbol(symbols, strings.TrimRight(match[1], ".-"))
+	}
+	return symbols
+}
+
+// symbolsFromMention - finds the symbols in "@stockbot MSFT?" or "@stockbot what are $MSFT and $IBM at?".
+// Cashtags win. Otherwise, a single word is taken as the symbol, and in a sentence only the upper-case words are.
+func symbolsFromMention(text string) []string {
+	if symbols := cashtags(text); len(symbols) > 0 {
+		return symbols
+	}
+
+	words := strings.FieldsFunc(mentionPattern.ReplaceAllString(text, " "), func(r rune) bool {
+		return r == ' ' || r == ',' || r == '\n' || r == '\t'
+	})
+
+	var symbols []string
+	for _, word := range words {
+		word = strings.TrimRight(word, "?!.:;")
+		if !mentionWordPattern.MatchString(word) || !letterPattern.MatchString(word) {
+			continue
+		}
+		// In a sentence, single letters are more likely to be "I" or "A" than Ford or AT&T
+		if len(words) == 1 || (len(word) > 1 && word == strings.ToUpper(word)) {
+			symbols = appendSymbol(symbols, word)
+		}
+	}
+	return symbols
+}
+
+func appendSymbol(symbols []string, symbol string) []string {
+	symbol = strings.ToUpper(symbol)
+	if symbol == "" || len(symbols) >= maxSymbolsPerReply {
+		return symbols
+	}
+	for _, s := range symbols {
+		if s == symbol {
+			return symbols
+		}
+	}
+	return append(symbols, symbol)
+}
+
+func mentionsAny(text string, users []string) bool {
+	for _, user := range users {
+		if strings.Contains(text, "<@"+user) {
+			return true
+		}
+	}
+	return false
+}
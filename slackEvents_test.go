@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSymbolsFromMention(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"single symbol", "<@UBOT> MSFT?", []string{"MSFT"}},
+		{"single lower-case symbol", "<@UBOT|stockbot> brk.b", []string{"BRK.B"}},
+		{"symbols in a sentence", "<@UBOT> how are MSFT, IBM and GE doing today?", []string{"MSFT", "IBM", "GE"}},
+		{"cashtags win", "<@UBOT> is $aapl higher than MSFT?", []string{"AAPL"}},
+		{"no symbols", "<@UBOT> hello there", nil},
+		{"numbers are not symbols", "<@UBOT> 130", nil},
+		{"single letters in a sentence are skipped", "<@UBOT> I want F", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := symbolsFromMention(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("symbolsFromMention() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCashtags(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"cashtags", "Thinking of buying $MSFT and ($ibm). $MSFT again.", []string{"MSFT", "IBM"}},
+		{"prices are not cashtags", "It costs $130.50", nil},
+		{"a dollar sign inside a word", "US$5 or abc$DEF", nil},
+		{"trailing punctuation", "What about $BRK.B.", []string{"BRK.B"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cashtags(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cashtags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleEventsRequest_RetryIsVerified(t *testing.T) {
+	body := `{"type":"event_callback","team_id":"T1","event":{"type":"app_mention","text":"<@UBOT> MSFT"}}`
+	r := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(body))
+	r.Header.Set("X-Slack-Retry-Num", "1")
+	r.Header.Set("X-Slack-Request-Timestamp", strconv.FormatInt(time.Now().Unix(), 10))
+	r.Header.Set("X-Slack-Signature", "v0="+strings.Repeat("0", 64))
+	w := httptest.NewRecorder()
+
+	handleEventsRequest(w, r, "secret")
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("handleEventsRequest() status = %d for a retry with a forged signature, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,39 @@
+package slackmessaging
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
+)
+
+// CheckNotificationConfig - says how the notifications are posted, or why they cannot be
+func CheckNotificationConfig() (string, error) {
+	return checkNotificationSettings(getAppSettings(), botTokenLookup != nil)
+}
+
+// checkNotificationSettings - a bot token is enough. Without one, both of the webhooks must be valid URLs.
+func checkNotificationSettings(settings *config.AppSettings, hasWorkspaceTokens bool) (string, error) {
+	if settings.SlackBotToken != "" {
+		return "notifications are posted with the bot token", nil
+	}
+	if hasWorkspaceTokens {
+		return "notifications are posted with the bot tokens of the installed workspaces", nil
+	}
+
+	var problems []string
+	for _, webhook := range []struct{ name, value string }{{"webhook", settings.Webhook}, {"dmWebhook", settings.DMWebhook}} {
+		if webhook.value == "" {
+			problems = append(problems, fmt.Sprintf("the %s is not set", webhook.name))
+			continue
+		}
+		if parsed, err := url.Parse(webhook.value); err != nil || parsed.Host == "" || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+			problems = append(problems, fmt.Sprintf("the %s is not a URL", webhook.name))
+		}
+	}
+	if len(problems) > 0 {
+		return "", fmt.Errorf("there is no slackBotToken, and %s", strings.Join(problems, " and "))
+	}
+	return "notifications are posted to the webhooks", nil
+}
@@ -0,0 +1,34 @@
+package slackmessaging
+
+import (
+	"testing"
+
+	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
+)
+
+func TestCheckNotificationSettings(t *testing.T) {
+	tests := []struct {
+		name               string
+		settings           config.AppSettings
+		hasWorkspaceTokens bool
+		wantErr            string
+	}{
+		{name: "bot token", settings: config.AppSettings{SlackBotToken: "xoxb-1"}},
+		{name: "installed workspaces", hasWorkspaceTokens: true},
+		{name: "webhooks", settings: config.AppSettings{Webhook: "https://hooks.slack.com/services/1", DMWebhook: "https://hooks.slack.com/services/2"}},
+		{name: "nothing", wantErr: "there is no slackBotToken, and the webhook is not set and the dmWebhook is not set"},
+		{name: "bad webhook", settings: config.AppSettings{Webhook: "hooks.slack.com", DMWebhook: "https://hooks.slack.com/services/2"},
+			wantErr: "there is no slackBotToken, and the webhook is not a URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := checkNotificationSettings(&tt.settings, tt.hasWorkspaceTokens)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("checkNotificationSettings() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("checkNotificationSettings() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
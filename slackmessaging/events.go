@@ -0,0 +1,65 @@
+package slackmessaging
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/nlopes/slack/slackevents"
+)
+
+// ProcessIncomingEvent - reads a request that Slack sends to the Events API endpoint, and verifies that it came from Slack.
+// The url_verification challenge is answered here. The caller only needs to handle the callback events.
+func ProcessIncomingEvent(r *http.Request, w http.ResponseWriter, signingSecret string) (event slackevents.EventsAPIEvent, errs error) {
+	// Create a SecretsVerifier
+	verifier, err := createVerifier(r, w, signingSecret)
+	if err != nil {
+		return event, err
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return event, err
+	}
+
+	// Verify that the request came from Slack
+	verifier.Write(body)
+	if err = verifier.Ensure(); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return event, err
+	}
+
+	// The signature has been checked, so there is no need to check the deprecated verification token
+	event, err = slackevents.ParseEvent(json.RawMessage(body), slackevents.OptionNoVerifyToken())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return event, err
+	}
+	logger.Debug("parsed the event", "type", event.Type, "inner_type", event.InnerEvent.Type, "team", event.TeamID)
+
+	if event.Type == slackevents.URLVerification {
+		challenge := new(slackevents.ChallengeResponse)
+		if err = json.Unmarshal(body, challenge); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return event, err
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(challenge.Challenge))
+	}
+
+	return event, nil
+}
+
+// PostThreadReply - replies to a message in its thread. Replies can only be posted through the Web API.
+func PostThreadReply(teamID string, slackChannel string, threadTimeStamp string, format SlackMessageFormat) error {
+	notifier, err := notifierForTeam(teamID)
+	if err != nil {
+		return err
+	}
+	if notifier == nil {
+		return errors.New("a thread reply cannot be posted without the SlackBotToken")
+	}
+	return notifier.PostThreadReply(slackChannel, threadTimeStamp, format)
+}
@@ -0,0 +1,105 @@
+package slackmessaging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/nlopes/slack"
+)
+
+// InteractionTypeViewSubmission - the interaction that Slack sends when the user submits a modal
+const InteractionTypeViewSubmission = slack.InteractionType("view_submission")
+
+// Interaction - an interaction from Slack. View is only filled in when a modal is submitted.
+type Interaction struct {
+	slack.InteractionCallback
+	View *ViewSubmission `json:"view"`
+}
+
+// ProcessIncomingInteraction - reads the payload that Slack sends when a user clicks on a button
+// or submits a modal, and verifies that the request came from Slack
+func ProcessIncomingInteraction(r *http.Request, w http.ResponseWriter, signingSecret string) (callback Interaction, errs error) {
+	// Create a SecretsVerifier
+	verifier, err := createVerifier(r, w, signingSecret)
+	if err != nil {
+		return callback, err
+	}
+
+	// The interaction is a form with a single field called "payload" that holds the Json
+	r.Body = ioutil.NopCloser(io.TeeReader(r.Body, &verifier))
+	if err = r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return callback, err
+	}
+
+	// Verify that the request came from Slack
+	if err = verifier.Ensure(); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return callback, err
+	}
+
+	if err = json.Unmarshal([]byte(r.PostForm.Get("payload")), &callback); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return callback, err
+	}
+	logger.Debug("parsed the interaction", "type", callback.Type, "user", callback.User.ID)
+
+	return callback, nil
+}
+
+// WriteBlockResponse - writes a Block Kit message to a ResponseWriter that Slack will receive
+func WriteBlockResponse(writer http.ResponseWriter, msg slack.Message) error {
+	msg.ResponseType = "ephemeral"
+	jsonValue, err := json.Marshal(msg.Msg)
+
+	// Was there a problem marshalling?
+	if err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		return err
+	}
+
+	// Send the output back to Slack
+	writer.Header().Set("Content-Type", "application/json")
+	_, err = writer.Write(jsonValue)
+	return err
+}
+
+// RespondToInteraction - sends a message to the response_url of an interaction.
+// If replaceOriginal is true, the message that holds the button that was clicked is replaced.
+func RespondToInteraction(responseURL string, msg slack.Message, replaceOriginal bool) error {
+	msg.ResponseType = "ephemeral"
+	msg.ReplaceOriginal = replaceOriginal
+
+	jsonValue, err := json.Marshal(msg.Msg)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.Post(responseURL, "application/json", bytes.NewReader(jsonValue))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("the response_url returned %s", resp.Status)
+	}
+	return nil
+}
+
+// OpenModal - opens a modal dialog. Modals can only be opened through the Web API, so the bot token is needed.
+func OpenModal(teamID string, triggerID string, modal SlackModal) error {
+	notifier, err := notifierForTeam(teamID)
+	if err != nil {
+		return err
+	}
+	if notifier == nil {
+		return errors.New("a modal cannot be opened without the SlackBotToken")
+	}
+	return notifier.OpenModal(triggerID, modal)
+}
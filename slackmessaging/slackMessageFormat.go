@@ -15,6 +15,33 @@ type SlackMessageFormat struct {
 	Footer  string
 	Color   string
 	UseTime bool
+	Items   []SlackMessageItem // only used by ToBlock
+	Fields  []SlackMessageField
+}
+
+// SlackMessageField - a label and a value, shown side by side with the other fields
+type SlackMessageField struct {
+	Title string
+	Value string
+}
+
+// Slack allows at most this many fields in a section
+const maxFieldsPerSection = 10
+
+// SlackMessageItem - a line in a message that can have buttons underneath it
+type SlackMessageItem struct {
+	BlockID string
+	Text    string
+	Buttons []SlackButton
+}
+
+// SlackButton - Slack-agnostic description of a button that sends an action back to the bot
+type SlackButton struct {
+	ActionID string
+	Value    string
+	Text     string
+	Style    string // "primary", "danger" or empty
+	Confirm  string // if present, Slack asks the user to confirm before sending the action
 }
 
 // ToAttachment - converts a SlackMessageFormat to a Slack Attachment
@@ -34,6 +61,10 @@ func (format *SlackMessageFormat) ToAttachment() *slack.Attachment {
 		attachment.Color = format.Color
 	}
 
+	for _, field := range format.Fields {
+		attachment.Fields = append(attachment.Fields, slack.AttachmentField{Title: field.Title, Value: field.Value, Short: true})
+	}
+
 	if format.UseTime {
 		attachment.Ts = json.Number(strconv.FormatInt(time.Now().Unix(), 10))
 	}
@@ -75,7 +106,26 @@ func (format *SlackMessageFormat) ToBlock() slack.Message {
 	}
 
 	// The main text
-	blocks = append(blocks, createTextBlock(format.Text))
+	if format.Text != "" || (len(format.Items) == 0 && len(format.Fields) == 0) {
+		blocks = append(blocks, createTextBlock(format.Text))
+	}
+
+	// The fields are laid out in two columns
+	for start := 0; start < len(format.Fields); start += maxFieldsPerSection {
+		end := start + maxFieldsPerSection
+		if end > len(format.Fields) {
+			end = len(format.Fields)
+		}
+		blocks = append(blocks, createFieldsBlock(format.Fields[start:end]))
+	}
+
+	// Each item gets its own section, with the buttons in an actions block underneath
+	for _, item := range format.Items {
+		blocks = append(blocks, createTextBlock(item.Text))
+		if len(item.Buttons) > 0 {
+			blocks = append(blocks, createActionsBlock(item.BlockID, item.Buttons))
+		}
+	}
 
 	// The optional footer
 	if format.Footer != "" {
@@ -90,6 +140,35 @@ func createTextBlock(text string) *slack.SectionBlock {
 	return slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", text, false, false), nil, nil)
 }
 
+func createFieldsBlock(fields []SlackMessageField) *slack.SectionBlock {
+	var objects []*slack.TextBlockObject
+	for _, field := range fields {
+		objects = append(objects, slack.NewTextBlockObject("mrkdwn", "*"+field.Title+"*\n"+field.Value, false, false))
+	}
+	return slack.NewSectionBlock(nil, objects, nil)
+}
+
+func createActionsBlock(blockID string, buttons []SlackButton) *slack.ActionBlock {
+	var elements []slack.BlockElement
+
+	for _, button := range buttons {
+		element := slack.NewButtonBlockElement(button.ActionID, button.Value, slack.NewTextBlockObject("plain_text", button.Text, false, false))
+		if button.Style != "" {
+			element.WithStyle(slack.Style(button.Style))
+		}
+		if button.Confirm != "" {
+			element.Confirm = slack.NewConfirmationBlockObject(
+				slack.NewTextBlockObject("plain_text", "Are you sure?", false, false),
+				slack.NewTextBlockObject("mrkdwn", button.Confirm, false, false),
+				slack.NewTextBlockObject("plain_text", button.Text, false, false),
+				slack.NewTextBlockObject("plain_text", "Cancel", false, false))
+		}
+		elements = append(elements, element)
+	}
+
+	return slack.NewActionBlock(blockID, elements...)
+}
+
 func createContextBlock(text string) *slack.ContextBlock {
 	return slack.NewContextBlock("", []slack.MixedElement{
 		slack.NewTextBlockObject("mrkdwn", text, false, false),
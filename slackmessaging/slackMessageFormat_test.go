@@ -0,0 +1,75 @@
+package slackmessaging
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nlopes/slack"
+)
+
+func TestSlackMessageFormat_ToBlock(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    SlackMessageFormat
+		wantTypes []slack.MessageBlockType
+		wantJSON  []string
+	}{
+		{
+			"text only",
+			SlackMessageFormat{Text: "MSFT: 133.01"},
+			[]slack.MessageBlockType{slack.MBTSection},
+			[]string{`"text":"MSFT: 133.01"`},
+		},
+		{
+			"fields",
+			SlackMessageFormat{
+				Title:  "MSFT",
+				Fields: []SlackMessageField{{Title: "Market cap", Value: "1.02T"}, {Title: "P/E", Value: "28.10"}},
+			},
+			[]slack.MessageBlockType{slack.MBTSection, slack.MBTSection},
+			[]string{`"fields":[`, `"text":"*Market cap*\n1.02T"`},
+		},
+		{
+			"items with buttons",
+			SlackMessageFormat{
+				Title: "Your Price Alerts",
+				Items: []SlackMessageItem{
+					{BlockID: "alert_1", Text: "*MSFT*", Buttons: []SlackButton{{ActionID: "alert_delete", Value: "1", Text: "Delete", Style: "danger", Confirm: "Sure?"}}},
+					{BlockID: "alert_2", Text: "*IBM*"},
+				},
+				Footer: "footer",
+			},
+			[]slack.MessageBlockType{slack.MBTSection, slack.MBTSection, slack.MBTAction, slack.MBTSection, slack.MBTContext},
+			[]string{`"action_id":"alert_delete"`, `"value":"1"`, `"style":"danger"`, `"confirm":`, `"block_id":"alert_1"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := tt.format.ToBlock()
+
+			var gotTypes []slack.MessageBlockType
+			for _, block := range msg.Blocks.BlockSet {
+				gotTypes = append(gotTypes, block.BlockType())
+			}
+			if len(gotTypes) != len(tt.wantTypes) {
+				t.Fatalf("SlackMessageFormat.ToBlock() block types = %v, want %v", gotTypes, tt.wantTypes)
+			}
+			for i := range gotTypes {
+				if gotTypes[i] != tt.wantTypes[i] {
+					t.Errorf("SlackMessageFormat.ToBlock() block types = %v, want %v", gotTypes, tt.wantTypes)
+				}
+			}
+
+			payload, err := json.Marshal(msg.Msg)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			for _, want := range tt.wantJSON {
+				if !strings.Contains(string(payload), want) {
+					t.Errorf("the payload %s does not contain %s", payload, want)
+				}
+			}
+		})
+	}
+}
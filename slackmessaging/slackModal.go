@@ -0,0 +1,159 @@
+package slackmessaging
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// The kinds of inputs that a SlackModal can have
+const (
+	ModalInputText    = "text"
+	ModalInputSelect  = "select"
+	ModalInputChannel = "channel"
+	ModalInputDate    = "date"
+)
+
+// SlackModal - Slack-agnostic description of a modal dialog
+type SlackModal struct {
+	CallbackID      string
+	Title           string
+	SubmitText      string
+	PrivateMetadata string // comes back untouched in the view_submission
+	Inputs          []SlackModalInput
+}
+
+// SlackModalInput - a labelled input in a modal. The Name comes back as the key of the submitted value.
+type SlackModalInput struct {
+	Name         string
+	Label        string
+	Kind         string // one of the ModalInput constants. Defaults to ModalInputText.
+	Placeholder  string
+	InitialValue string
+	Options      []string // the choices for a ModalInputSelect
+	Optional     bool
+}
+
+// ViewSubmission - the view that Slack sends back when the user submits a modal
+type ViewSubmission struct {
+	ID              string `json:"id"`
+	CallbackID      string `json:"callback_id"`
+	PrivateMetadata string `json:"private_metadata"`
+	State           struct {
+		Values map[string]map[string]viewStateValue `json:"values"`
+	} `json:"state"`
+}
+
+type viewStateValue struct {
+	Type           string `json:"type"`
+	Value          string `json:"value"`
+	SelectedOption *struct {
+		Value string `json:"value"`
+	} `json:"selected_option"`
+	SelectedChannel string `json:"selected_channel"`
+	SelectedDate    string `json:"selected_date"`
+}
+
+// Value - returns the submitted value of the input with the given name, whatever kind of input it was
+func (view *ViewSubmission) Value(name string) string {
+	state, ok := view.State.Values[name][name]
+	if !ok {
+		return ""
+	}
+
+	switch {
+	case state.SelectedOption != nil:
+		return state.SelectedOption.Value
+	case state.SelectedChannel != "":
+		return state.SelectedChannel
+	case state.SelectedDate != "":
+		return state.SelectedDate
+	default:
+		return state.Value
+	}
+}
+
+// WriteViewErrors - tells Slack to keep the modal open and show an error message under each of the named inputs
+func WriteViewErrors(writer http.ResponseWriter, errors map[string]string) error {
+	jsonValue, err := json.Marshal(map[string]interface{}{
+		"response_action": "errors",
+		"errors":          errors,
+	})
+	if err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		return err
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_, err = writer.Write(jsonValue)
+	return err
+}
+
+// ToView - converts a SlackModal to the Json view that views.open expects
+func (modal *SlackModal) ToView() map[string]interface{} {
+	var blocks []interface{}
+
+	for _, input := range modal.Inputs {
+		blocks = append(blocks, map[string]interface{}{
+			"type":     "input",
+			"block_id": input.Name,
+			"label":    plainText(input.Label),
+			"optional": input.Optional,
+			"element":  input.toElement(),
+		})
+	}
+
+	view := map[string]interface{}{
+		"type":        "modal",
+		"callback_id": modal.CallbackID,
+		"title":       plainText(modal.Title),
+		"submit":      plainText(modal.SubmitText),
+		"close":       plainText("Cancel"),
+		"blocks":      blocks,
+	}
+	if modal.PrivateMetadata != "" {
+		view["private_metadata"] = modal.PrivateMetadata
+	}
+
+	return view
+}
+
+func (input *SlackModalInput) toElement() map[string]interface{} {
+	element := map[string]interface{}{"action_id": input.Name}
+	if input.Placeholder != "" {
+		element["placeholder"] = plainText(input.Placeholder)
+	}
+
+	switch input.Kind {
+	case ModalInputSelect:
+		element["type"] = "static_select"
+		var options []interface{}
+		for _, option := range input.Options {
+			options = append(options, map[string]interface{}{"text": plainText(option), "value": option})
+			if option == input.InitialValue {
+				element["initial_option"] = options[len(options)-1]
+			}
+		}
+		element["options"] = options
+	case ModalInputChannel:
+		element["type"] = "channels_select"
+		if input.InitialValue != "" {
+			element["initial_channel"] = input.InitialValue
+		}
+	case ModalInputDate:
+		element["type"] = "datepicker"
+		if input.InitialValue != "" {
+			element["initial_date"] = input.InitialValue
+		}
+	default:
+		element["type"] = "plain_text_input"
+		if input.InitialValue != "" {
+			element["initial_value"] = input.InitialValue
+		}
+	}
+
+	return element
+}
+
+func plainText(text string) map[string]interface{} {
+	return map[string]interface{}{"type": "plain_text", "text": text}
+}
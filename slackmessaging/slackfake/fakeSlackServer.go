@@ -0,0 +1,96 @@
+// Package slackfake - a local stand-in for the Slack Web API that tests can point a slack.Client at
+package slackfake
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// FakeSlackRequest - a call that was made to the fake Slack server
+type FakeSlackRequest struct {
+	Method string     // the Web API method, e.g. "chat.postMessage"
+	Values url.Values // the form values that were posted
+	Body   []byte     // the raw body, for methods that take JSON
+}
+
+// FakeSlackServer - records every Web API call and answers with canned responses
+type FakeSlackServer struct {
+	*httptest.Server
+	mutex     sync.Mutex
+	requests  []FakeSlackRequest
+	responses map[string]interface{}
+}
+
+// CreateFakeSlackServer - starts a new fake Slack server. Call Close() when done.
+func CreateFakeSlackServer() *FakeSlackServer {
+	server := &FakeSlackServer{responses: make(map[string]interface{})}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.handle))
+	return server
+}
+
+// APIURL - the url to pass to slack.OptionAPIURL
+func (server *FakeSlackServer) APIURL() string {
+	return server.URL + "/"
+}
+
+// SetResponse - overrides the response that the server sends back for a Web API method
+func (server *FakeSlackServer) SetResponse(method string, response interface{}) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	server.responses[method] = response
+}
+
+// Calls - returns all of the calls that were made to a Web API method
+func (server *FakeSlackServer) Calls(method string) []FakeSlackRequest {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	var calls []FakeSlackRequest
+	for _, request := range server.requests {
+		if request.Method == method {
+			calls = append(calls, request)
+		}
+	}
+	return calls
+}
+
+func (server *FakeSlackServer) handle(w http.ResponseWriter, r *http.Request) {
+	method := strings.TrimPrefix(r.URL.Path, "/")
+	body, _ := ioutil.ReadAll(r.Body)
+
+	request := FakeSlackRequest{Method: method, Body: body}
+	if values, err := url.ParseQuery(string(body)); err == nil && !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		request.Values = values
+	}
+
+	server.mutex.Lock()
+	server.requests = append(server.requests, request)
+	response, ok := server.responses[method]
+	server.mutex.Unlock()
+
+	if !ok {
+		response = defaultResponse(method, request.Values)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// defaultResponse - a successful response for the Web API methods that the bot uses
+func defaultResponse(method string, values url.Values) interface{} {
+	switch method {
+	case "chat.postMessage":
+		return map[string]interface{}{"ok": true, "channel": values.Get("channel"), "ts": "1561234567.000100"}
+	case "conversations.open":
+		return map[string]interface{}{"ok": true, "channel": map[string]interface{}{"id": "D" + values.Get("users")}}
+	case "auth.test":
+		return map[string]interface{}{"ok": true, "user_id": "UBOT", "team_id": "TFAKE"}
+	default:
+		return map[string]interface{}{"ok": false, "error": "unknown_method"}
+	}
+}
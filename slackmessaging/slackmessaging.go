@@ -3,32 +3,121 @@ package slackmessaging
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"strings"
+	"sync"
 
 	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
+	"github.com/magmasystems/SlackStockSlashCommand/framework/logging"
+	"github.com/magmasystems/SlackStockSlashCommand/metrics"
 	"github.com/nlopes/slack"
 )
 
-var appSettings *config.AppSettings
+var (
+	settingsLock   sync.RWMutex // Configure is called again when the appSettings are reloaded
+	appSettings    = &config.AppSettings{}
+	webAPINotifier *WebAPINotifier
+	botTokenLookup func(teamID string) (string, error)
+	logger         = logging.For("slackmessaging")
+)
+
+var notificationsSent = metrics.CreateCounter("stockbot_notifications_total",
+	"The notifications posted to Slack, by transport (webapi or webhook) and result (sent or failed).", "transport", "result")
+
+// NotifyFunc - posts a notification to a user or a channel of a workspace, like PostSlackNotificationToTeam does.
+// The packages that send notifications take one, so that their tests can see what would have been posted.
+type NotifyFunc func(teamID string, slackUserName string, slackChannel string, format SlackMessageFormat)
+
+// Configure - sets the webhooks and the bot token that the notifications are posted with.
+// Until it is called, there are no webhooks and no bot token. It can be called again with reloaded appSettings,
+// and the notifications that are posted afterwards go to the new webhooks, or with the new bot token.
+func Configure(settings *config.AppSettings) {
+	var notifier *WebAPINotifier
+	if settings.SlackBotToken != "" {
+		notifier = CreateWebAPINotifier(settings.SlackBotToken, settings.SlackAPIURL)
+	}
+
+	settingsLock.Lock()
+	appSettings = settings
+	webAPINotifier = notifier
+	settingsLock.Unlock()
+}
+
+// SetBotTokenLookup - when the app is installed into several workspaces through OAuth,
+// each workspace has its own bot token. The lookup finds the token of a team.
+func SetBotTokenLookup(lookup func(teamID string) (string, error)) {
+	botTokenLookup = lookup
+}
+
+// ErrWorkspaceNotInstalled - the bot has no token for the workspace, which is not the home workspace either.
+// That happens after its token was revoked. Its notifications must not go to the home workspace instead.
+var ErrWorkspaceNotInstalled = errors.New("the bot is not installed in the workspace")
+
+// notifierForTeam - the Web API notifier for the workspace, or the one for the SlackBotToken in the appSettings.
+// Only the home team, and the alerts from before OAuth, which have no team, fall back to the SlackBotToken.
+// Returns nil if the fallback has no bot token, in which case we fall back to the webhooks.
+func notifierForTeam(teamID string) (*WebAPINotifier, error) {
+	settings := getAppSettings()
+	defaultNotifier := getWebAPINotifier()
+
+	if teamID == "" || botTokenLookup == nil {
+		return defaultNotifier, nil
+	}
+
+	botToken, err := botTokenLookup(teamID)
+	if err != nil {
+		return nil, err
+	}
+	if botToken != "" {
+		return CreateWebAPINotifier(botToken, settings.SlackAPIURL), nil
+	}
+	if teamID == settings.OAuth.HomeTeamID {
+		return defaultNotifier, nil
+	}
+	return nil, ErrWorkspaceNotInstalled
+}
+
+// getAppSettings - the appSettings that were passed to Configure
+func getAppSettings() *config.AppSettings {
+	settingsLock.RLock()
+	defer settingsLock.RUnlock()
+	return appSettings
+}
+
+// getWebAPINotifier - the notifier for the SlackBotToken in the appSettings. Nil if there is no bot token.
+func getWebAPINotifier() *WebAPINotifier {
+	settingsLock.RLock()
+	defer settingsLock.RUnlock()
+	return webAPINotifier
+}
+
+// ErrNoSigningSecret - the request cannot be verified, because there is no slackSecret in the appSettings
+var ErrNoSigningSecret = errors.New("there is no signing secret to verify the request with")
 
-func init() {
-	// Fetch the appSettings when we load this, because we need to get the Webhooks
-	configMgr := new(config.ConfigManager)
-	appSettings = configMgr.Config()
+// createVerifier - checks the signature of a request from Slack. Without a signing secret, every request is refused,
+// because a signature made with an empty key is one that anybody can make.
+func createVerifier(r *http.Request, w http.ResponseWriter, signingSecret string) (slack.SecretsVerifier, error) {
+	if signingSecret == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return slack.SecretsVerifier{}, ErrNoSigningSecret
+	}
+
+	verifier, err := slack.NewSecretsVerifier(r.Header, signingSecret)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	return verifier, err
 }
 
 // ProcessIncomingSlashCommand - reads the incoming request and create a Slash Command
 func ProcessIncomingSlashCommand(r *http.Request, w http.ResponseWriter, signingSecret string) (slashCommand slack.SlashCommand, errs error) {
 	// Create a SecretsVerifier
-	verifier, err := slack.NewSecretsVerifier(r.Header, signingSecret)
+	verifier, err := createVerifier(r, w, signingSecret)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return slashCommand, err
 	}
 
 	// Get the command body from the request and parse it into a new Slash Command
@@ -38,7 +127,7 @@ func ProcessIncomingSlashCommand(r *http.Request, w http.ResponseWriter, signing
 		w.WriteHeader(http.StatusInternalServerError)
 		return slashCommand, err
 	}
-	log.Printf("The slash command is %s and the text is %s\n", slashCommand.Command, slashCommand.Text)
+	logger.Debug("parsed the slash command", "command", slashCommand.Command, "text", slashCommand.Text)
 
 	// Verify that the request came from Slack
 	if err = verifier.Ensure(); err != nil {
@@ -82,16 +171,49 @@ func PostSlackNotification(slackUserName string, slackChannel string, outputText
 
 // PostSlackNotificationFormatted - posts a message to Slack, but accepts a Slack Attachment as an argument.
 // This gives the user the ability to pass in a format that has lots of options.
+// If there is a bot token in the appSettings, the message goes to the actual channel or user through the Web API.
 func PostSlackNotificationFormatted(slackUserName string, slackChannel string, format SlackMessageFormat) {
+	PostSlackNotificationToTeam("", slackUserName, slackChannel, format)
+}
+
+// PostSlackNotificationToTeam - posts a formatted message with the bot token of the workspace that the user belongs to
+func PostSlackNotificationToTeam(teamID string, slackUserName string, slackChannel string, format SlackMessageFormat) {
+	settings := getAppSettings()
+
+	notifier, err := notifierForTeam(teamID)
+	if err == ErrWorkspaceNotInstalled {
+		logger.Warn("skipped the notification of a workspace that the bot is no longer installed in", "team", teamID, "user", slackUserName)
+		notificationsSent.Inc("webapi", "skipped")
+		return
+	}
+	if err != nil {
+		logger.Error("cannot find the bot token of the team", "team", teamID, "err", err)
+		notificationsSent.Inc("webapi", "failed")
+		return
+	}
+
+	if notifier != nil {
+		if err = notifier.PostNotificationFormatted(slackUserName, slackChannel, format); err != nil {
+			logger.Error("cannot post the notification", "team", teamID, "user", slackUserName, "channel", slackChannel, "err", err)
+			notificationsSent.Inc("webapi", "failed")
+		} else {
+			notificationsSent.Inc("webapi", "sent")
+		}
+		return
+	}
+
 	msg := slack.WebhookMessage{
 		Attachments: []slack.Attachment{*format.ToAttachment()},
 	}
 
-	webhook := getWebhook(slackChannel, appSettings)
+	webhook := getWebhook(slackChannel, settings)
 
-	err := slack.PostWebhook(webhook, &msg)
+	err = slack.PostWebhook(webhook, &msg)
 	if err != nil {
-		fmt.Println(err)
+		logger.Error("cannot post the notification to the webhook", "user", slackUserName, "channel", slackChannel, "err", err)
+		notificationsSent.Inc("webhook", "failed")
+	} else {
+		notificationsSent.Inc("webhook", "sent")
 	}
 }
 
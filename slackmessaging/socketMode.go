@@ -0,0 +1,276 @@
+package slackmessaging
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/nlopes/slack"
+	"github.com/nlopes/slack/slackevents"
+)
+
+// The types of the envelopes that Slack sends over a Socket Mode connection
+const (
+	envelopeHello         = "hello"
+	envelopeDisconnect    = "disconnect"
+	envelopeSlashCommands = "slash_commands"
+	envelopeInteractive   = "interactive"
+	envelopeEventsAPI     = "events_api"
+)
+
+// SocketModeHandlers - the dispatch functions that the Socket Mode client feeds the envelopes to.
+// They are the same ones that the HTTP endpoints use once they have verified a request.
+type SocketModeHandlers struct {
+	SlashCommand func(slashCommand slack.SlashCommand, w http.ResponseWriter)
+	Interaction  func(interaction Interaction, w http.ResponseWriter)
+	Event        func(event slackevents.EventsAPIEvent)
+}
+
+// SocketModeClient - receives slash commands, interactions and events over an outbound WebSocket,
+// so that the bot does not have to be reachable from the internet
+type SocketModeClient struct {
+	appToken string
+	apiURL   string
+	handlers SocketModeHandlers
+}
+
+type socketModeEnvelope struct {
+	Type                   string          `json:"type"`
+	EnvelopeID             string          `json:"envelope_id"`
+	Payload                json.RawMessage `json:"payload"`
+	AcceptsResponsePayload bool            `json:"accepts_response_payload"`
+	Reason                 string          `json:"reason"`
+}
+
+type socketModeAck struct {
+	EnvelopeID string          `json:"envelope_id"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+}
+
+// CreateSocketModeClient - creates a client for the app-level token (xapp-...).
+// The apiURL is only needed to talk to a fake Slack server.
+func CreateSocketModeClient(appToken string, apiURL string, handlers SocketModeHandlers) *SocketModeClient {
+	if apiURL == "" {
+		apiURL = slack.APIURL
+	}
+	return &SocketModeClient{appToken: appToken, apiURL: apiURL, handlers: handlers}
+}
+
+// Run - connects to Slack and dispatches envelopes until the stop channel is closed.
+// Slack asks us to reconnect every few hours, and we also reconnect after any error.
+func (client *SocketModeClient) Run(stop <-chan struct{}) {
+	backoff := time.Second
+
+	for {
+		err := client.connectAndServe(stop)
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		if err == nil {
+			backoff = time.Second
+			continue
+		}
+
+		logger.Warn("the Socket Mode connection failed", "err", err, "retry_in", backoff)
+		select {
+		case <-stop:
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < time.Minute {
+			backoff *= 2
+		}
+	}
+}
+
+// connectAndServe - handles one connection. Returns nil if Slack asked us to reconnect.
+func (client *SocketModeClient) connectAndServe(stop <-chan struct{}) error {
+	url, err := client.openConnection()
+	if err != nil {
+		return err
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Each envelope is dispatched on its own, so that a slow slash command does not hold up the acks of the
+	// envelopes behind it. The envelopes that are being handled are acknowledged before the connection is closed.
+	writer := &ackWriter{conn: conn}
+	var inFlight sync.WaitGroup
+	defer inFlight.Wait()
+
+	// Closing the connection unblocks the read when we are asked to stop
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		envelope := socketModeEnvelope{}
+		if err = conn.ReadJSON(&envelope); err != nil {
+			return err
+		}
+
+		switch envelope.Type {
+		case envelopeHello:
+			logger.Info("connected to Slack with Socket Mode")
+		case envelopeDisconnect:
+			logger.Info("Slack asked us to reconnect", "reason", envelope.Reason)
+			return nil
+		default:
+			inFlight.Add(1)
+			go func(envelope socketModeEnvelope) {
+				defer inFlight.Done()
+				if err := client.dispatch(writer, envelope); err != nil {
+					// The next read fails too, and we reconnect
+					logger.Warn("cannot acknowledge the envelope", "envelope", envelope.EnvelopeID, "err", err)
+					conn.Close()
+				}
+			}(envelope)
+		}
+	}
+}
+
+// openConnection - calls apps.connections.open to get the url of the WebSocket
+func (client *SocketModeClient) openConnection() (string, error) {
+	req, err := http.NewRequest("POST", client.apiURL+"apps.connections.open", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+client.appToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	response := struct {
+		slack.SlackResponse
+		URL string `json:"url"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", err
+	}
+	if err = response.Err(); err != nil {
+		return "", err
+	}
+	if response.URL == "" {
+		return "", errors.New("apps.connections.open did not return a url")
+	}
+	return response.URL, nil
+}
+
+// ackWriter - lets the envelopes that are dispatched at the same time share the connection. A WebSocket has one writer at a time.
+type ackWriter struct {
+	mutex sync.Mutex
+	conn  *websocket.Conn
+}
+
+func (writer *ackWriter) WriteJSON(ack socketModeAck) error {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	return writer.conn.WriteJSON(ack)
+}
+
+// dispatch - hands the envelope to the right handler, and acknowledges it with whatever the handler wrote
+func (client *SocketModeClient) dispatch(writer *ackWriter, envelope socketModeEnvelope) error {
+	w := newEnvelopeResponseWriter()
+
+	switch envelope.Type {
+	case envelopeSlashCommands:
+		slashCommand := slack.SlashCommand{}
+		if err := json.Unmarshal(envelope.Payload, &slashCommand); err != nil {
+			logger.Warn("cannot parse the slash command of an envelope", "err", err)
+			break
+		}
+		if client.handlers.SlashCommand != nil {
+			client.handlers.SlashCommand(slashCommand, w)
+		}
+
+	case envelopeInteractive:
+		interaction := Interaction{}
+		if err := json.Unmarshal(envelope.Payload, &interaction); err != nil {
+			logger.Warn("cannot parse the interaction of an envelope", "err", err)
+			break
+		}
+		if client.handlers.Interaction != nil {
+			client.handlers.Interaction(interaction, w)
+		}
+
+	case envelopeEventsAPI:
+		event, err := slackevents.ParseEvent(envelope.Payload, slackevents.OptionNoVerifyToken())
+		if err != nil {
+			logger.Warn("cannot parse the event of an envelope", "err", err)
+			break
+		}
+		// Events are acknowledged first, just like the HTTP endpoint does
+		if err = writer.WriteJSON(socketModeAck{EnvelopeID: envelope.EnvelopeID}); err != nil {
+			return err
+		}
+		if client.handlers.Event != nil {
+			go client.handlers.Event(event)
+		}
+		return nil
+
+	default:
+		logger.Debug("ignoring an envelope", "type", envelope.Type)
+	}
+
+	ack := socketModeAck{EnvelopeID: envelope.EnvelopeID}
+	if envelope.AcceptsResponsePayload || envelope.Type == envelopeInteractive {
+		ack.Payload = w.payload()
+	}
+	return writer.WriteJSON(ack)
+}
+
+// envelopeResponseWriter - collects what a handler writes, so that it can be sent back as the payload of the ack
+type envelopeResponseWriter struct {
+	header http.Header
+	status int
+	body   []byte
+}
+
+func newEnvelopeResponseWriter() *envelopeResponseWriter {
+	return &envelopeResponseWriter{header: make(http.Header), status: http.StatusOK}
+}
+
+func (w *envelopeResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *envelopeResponseWriter) Write(b []byte) (int, error) {
+	w.body = append(w.body, b...)
+	return len(b), nil
+}
+
+func (w *envelopeResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+// payload - the Json that the handler wrote, if it wrote any
+func (w *envelopeResponseWriter) payload() json.RawMessage {
+	if w.status != http.StatusOK {
+		logger.Warn("the handler of the envelope failed", "status", w.status)
+		return nil
+	}
+	if len(w.body) == 0 || !json.Valid(w.body) {
+		return nil
+	}
+	return json.RawMessage(w.body)
+}
@@ -0,0 +1,117 @@
+package slackmessaging
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/magmasystems/SlackStockSlashCommand/slackmessaging/slackfake"
+	"github.com/nlopes/slack"
+)
+
+func TestSocketModeClient_Run(t *testing.T) {
+	acks := make(chan socketModeAck, 2)
+
+	// The WebSocket end of the fake Slack server sends a hello, a slash command and an interaction
+	upgrader := websocket.Upgrader{}
+	wsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Upgrade() error = %v", err)
+			return
+		}
+		defer conn.Close()
+
+		conn.WriteJSON(map[string]interface{}{"type": "hello"})
+		conn.WriteJSON(map[string]interface{}{
+			"type": "slash_commands", "envelope_id": "env-1", "accepts_response_payload": true,
+			"payload": map[string]string{"command": "/quote", "text": "MSFT", "user_id": "UKBM681GV"},
+		})
+		conn.WriteJSON(map[string]interface{}{
+			"type": "interactive", "envelope_id": "env-2",
+			"payload": map[string]interface{}{"type": "view_submission", "user": map[string]string{"id": "UKBM681GV"}, "view": map[string]string{"callback_id": "alert_modal"}},
+		})
+
+		for i := 0; i < 2; i++ {
+			ack := socketModeAck{}
+			if err := conn.ReadJSON(&ack); err != nil {
+				return
+			}
+			acks <- ack
+		}
+	}))
+	defer wsServer.Close()
+
+	server := slackfake.CreateFakeSlackServer()
+	defer server.Close()
+	server.SetResponse("apps.connections.open", map[string]interface{}{"ok": true, "url": "ws" + strings.TrimPrefix(wsServer.URL, "http")})
+
+	// The slash command is slow: it only finishes once the interaction behind it has been handled
+	interactionHandled := make(chan struct{})
+	client := CreateSocketModeClient("xapp-test", server.APIURL(), SocketModeHandlers{
+		SlashCommand: func(slashCommand slack.SlashCommand, w http.ResponseWriter) {
+			<-interactionHandled
+			WriteResponse(w, slashCommand.Command+" "+slashCommand.Text)
+		},
+		Interaction: func(interaction Interaction, w http.ResponseWriter) {
+			WriteViewErrors(w, map[string]string{"price": interaction.View.CallbackID})
+			close(interactionHandled)
+		},
+	})
+
+	stop := make(chan struct{})
+	go client.Run(stop)
+	defer close(stop)
+
+	want := map[string]string{
+		"env-1": `"text":"/quote MSFT"`,
+		"env-2": `"errors":{"price":"alert_modal"}`,
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case ack := <-acks:
+			if i == 0 && ack.EnvelopeID != "env-2" {
+				t.Errorf("%s was acknowledged first, want the interaction that was not held up by the slash command", ack.EnvelopeID)
+			}
+			if !strings.Contains(string(ack.Payload), want[ack.EnvelopeID]) {
+				t.Errorf("the ack of %s has the payload %s, want %s", ack.EnvelopeID, ack.Payload, want[ack.EnvelopeID])
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("the envelopes were not acknowledged")
+		}
+	}
+
+	if calls := server.Calls("apps.connections.open"); len(calls) == 0 {
+		t.Errorf("apps.connections.open was not called")
+	}
+}
+
+func TestEnvelopeResponseWriter_payload(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"json", http.StatusOK, `{"text":"hi"}`, `{"text":"hi"}`},
+		{"empty", http.StatusOK, "", ""},
+		{"not json", http.StatusOK, "hi", ""},
+		{"error", http.StatusInternalServerError, `{"text":"hi"}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newEnvelopeResponseWriter()
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+
+			got, _ := json.Marshal(w.payload())
+			if tt.want == "" && w.payload() != nil || tt.want != "" && string(got) != tt.want {
+				t.Errorf("envelopeResponseWriter.payload() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
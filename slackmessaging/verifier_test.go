@@ -0,0 +1,64 @@
+package slackmessaging
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// signedRequest - a request to Slack's endpoints, signed with the secret as Slack would sign it
+func signedRequest(body string, secret string) *http.Request {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+	r := httptest.NewRequest(http.MethodPost, "/quote", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	r.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return r
+}
+
+func TestProcessIncoming_SigningSecret(t *testing.T) {
+	processors := map[string]func(r *http.Request, w http.ResponseWriter, signingSecret string) error{
+		"slash command": func(r *http.Request, w http.ResponseWriter, secret string) error {
+			_, err := ProcessIncomingSlashCommand(r, w, secret)
+			return err
+		},
+		"interaction": func(r *http.Request, w http.ResponseWriter, secret string) error {
+			_, err := ProcessIncomingInteraction(r, w, secret)
+			return err
+		},
+		"event": func(r *http.Request, w http.ResponseWriter, secret string) error {
+			_, err := ProcessIncomingEvent(r, w, secret)
+			return err
+		},
+	}
+	tests := []struct {
+		name       string
+		signedWith string
+		secret     string
+		wantStatus int
+	}{
+		// Anybody can sign a request with an empty key, so an empty secret must not accept it
+		{"no signing secret", "", "", http.StatusUnauthorized},
+		{"wrong signing secret", "forged", "shh", http.StatusUnauthorized},
+	}
+	for name, process := range processors {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				err := process(signedRequest("command=%2Fquote&text=MSFT&payload=%7B%7D", tt.signedWith), w, tt.secret)
+				if err == nil || w.Code != tt.wantStatus {
+					t.Errorf("status = %d, error = %v, want %d and an error", w.Code, err, tt.wantStatus)
+				}
+			})
+		}
+	}
+}
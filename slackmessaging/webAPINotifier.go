@@ -0,0 +1,91 @@
+package slackmessaging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/nlopes/slack"
+)
+
+// WebAPINotifier - posts notifications with chat.postMessage, using the bot token instead of the fixed webhooks
+type WebAPINotifier struct {
+	client   *slack.Client
+	botToken string
+	apiURL   string
+}
+
+// CreateWebAPINotifier - creates a notifier for the bot token. The apiURL is only needed to talk to a fake Slack server.
+func CreateWebAPINotifier(botToken string, apiURL string) *WebAPINotifier {
+	options := []slack.Option{}
+	if apiURL != "" {
+		options = append(options, slack.OptionAPIURL(apiURL))
+	} else {
+		apiURL = slack.APIURL
+	}
+
+	return &WebAPINotifier{client: slack.New(botToken, options...), botToken: botToken, apiURL: apiURL}
+}
+
+// PostNotificationFormatted - posts to the channel that the alert names, or opens a DM with the user if there is no channel
+func (notifier *WebAPINotifier) PostNotificationFormatted(slackUserID string, slackChannel string, format SlackMessageFormat) error {
+	channelID := strings.Trim(slackChannel, " ")
+
+	if channelID == "" {
+		if slackUserID == "" {
+			return errors.New("the notification has neither a channel nor a user")
+		}
+
+		dm, _, _, err := notifier.client.OpenConversation(&slack.OpenConversationParameters{Users: []string{slackUserID}})
+		if err != nil {
+			return err
+		}
+		channelID = dm.ID
+	}
+
+	_, _, err := notifier.client.PostMessage(channelID, slack.MsgOptionAttachments(*format.ToAttachment()))
+	return err
+}
+
+// OpenModal - opens a modal dialog in response to a slash command or a button click
+func (notifier *WebAPINotifier) OpenModal(triggerID string, modal SlackModal) error {
+	return notifier.postJSON("views.open", map[string]interface{}{
+		"trigger_id": triggerID,
+		"view":       modal.ToView(),
+	})
+}
+
+// postJSON - calls a Web API method that the slack package does not know about yet
+func (notifier *WebAPINotifier) postJSON(method string, body interface{}) error {
+	jsonValue, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", notifier.apiURL+method, bytes.NewReader(jsonValue))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+notifier.botToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	response := slack.SlackResponse{}
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return err
+	}
+	return response.Err()
+}
+
+// PostThreadReply - posts a message into the thread of another message
+func (notifier *WebAPINotifier) PostThreadReply(slackChannel string, threadTimeStamp string, format SlackMessageFormat) error {
+	_, _, err := notifier.client.PostMessage(slackChannel, slack.MsgOptionText(format.Text, false), slack.MsgOptionTS(threadTimeStamp))
+	return err
+}
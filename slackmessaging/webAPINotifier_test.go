@@ -0,0 +1,111 @@
+package slackmessaging
+
+import (
+	"testing"
+
+	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
+	"github.com/magmasystems/SlackStockSlashCommand/slackmessaging/slackfake"
+)
+
+func TestWebAPINotifier_PostNotificationFormatted(t *testing.T) {
+	type args struct {
+		slackUserID  string
+		slackChannel string
+	}
+	tests := []struct {
+		name        string
+		args        args
+		wantChannel string
+		wantDM      bool
+		wantErr     bool
+	}{
+		{"posts to the alert's channel", args{"UKBM681GV", "#myalerts"}, "#myalerts", false, false},
+		{"opens a DM when there is no channel", args{"UKBM681GV", " "}, "DUKBM681GV", true, false},
+		{"needs a channel or a user", args{"", ""}, "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := slackfake.CreateFakeSlackServer()
+			defer server.Close()
+
+			notifier := CreateWebAPINotifier("xoxb-test", server.APIURL())
+			format := SlackMessageFormat{Text: "MSFT has gone ABOVE the target price", Title: "Price Target Reached"}
+
+			err := notifier.PostNotificationFormatted(tt.args.slackUserID, tt.args.slackChannel, format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WebAPINotifier.PostNotificationFormatted() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if opened := server.Calls("conversations.open"); (len(opened) == 1) != tt.wantDM {
+				t.Errorf("conversations.open was called %d times, wantDM %v", len(opened), tt.wantDM)
+			}
+
+			posts := server.Calls("chat.postMessage")
+			if len(posts) != 1 {
+				t.Fatalf("chat.postMessage was called %d times, want 1", len(posts))
+			}
+			if got := posts[0].Values.Get("channel"); got != tt.wantChannel {
+				t.Errorf("chat.postMessage channel = %v, want %v", got, tt.wantChannel)
+			}
+			if got := posts[0].Values.Get("token"); got != "xoxb-test" {
+				t.Errorf("chat.postMessage token = %v, want xoxb-test", got)
+			}
+		})
+	}
+}
+
+func TestWebAPINotifier_PostNotificationFormatted_SlackError(t *testing.T) {
+	server := slackfake.CreateFakeSlackServer()
+	defer server.Close()
+	server.SetResponse("chat.postMessage", map[string]interface{}{"ok": false, "error": "channel_not_found"})
+
+	notifier := CreateWebAPINotifier("xoxb-test", server.APIURL())
+	if err := notifier.PostNotificationFormatted("UKBM681GV", "#nowhere", SlackMessageFormat{Text: "hi"}); err == nil {
+		t.Errorf("WebAPINotifier.PostNotificationFormatted() expected the channel_not_found error")
+	}
+}
+
+func TestNotifierForTeam(t *testing.T) {
+	server := slackfake.CreateFakeSlackServer()
+	defer server.Close()
+
+	Configure(&config.AppSettings{SlackBotToken: "xoxb-default", SlackAPIURL: server.APIURL(), OAuth: config.OAuthSettings{HomeTeamID: "THOME"}})
+	defer Configure(&config.AppSettings{})
+
+	SetBotTokenLookup(func(teamID string) (string, error) {
+		if teamID == "T123" {
+			return "xoxb-t123", nil
+		}
+		return "", nil
+	})
+	defer SetBotTokenLookup(nil)
+
+	tests := []struct {
+		name      string
+		teamID    string
+		wantToken string
+		wantErr   error
+	}{
+		{"installed workspace", "T123", "xoxb-t123", nil},
+		{"home workspace", "THOME", "xoxb-default", nil},
+		{"no workspace", "", "xoxb-default", nil},
+		{"a workspace whose token was revoked", "T999", "", ErrWorkspaceNotInstalled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier, err := notifierForTeam(tt.teamID)
+			if err != tt.wantErr {
+				t.Fatalf("notifierForTeam() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if notifier.botToken != tt.wantToken {
+				t.Errorf("notifierForTeam() token = %v, want %v", notifier.botToken, tt.wantToken)
+			}
+		})
+	}
+}
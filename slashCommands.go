@@ -0,0 +1,179 @@
+package main
+
+import (
+	"time"
+
+	"github.com/magmasystems/SlackStockSlashCommand/commands"
+	"github.com/magmasystems/SlackStockSlashCommand/slackmessaging"
+	"github.com/nlopes/slack"
+)
+
+// createCommandRegistry - registers every slash command that the bot answers.
+// The help for each command is generated from what is registered here.
+func createCommandRegistry() *commands.Registry {
+	registry := commands.CreateRegistry()
+
+	mustRegister(registry, &commands.Command{
+		Name:        "/quote",
+		Aliases:     []string{"/quoted"},
+		Usage:       "symbol[,symbol,symbol,...] [in currency]",
+		Description: "Retrieves the current price one or more stocks. Each stock can be separated by a comma",
+		Examples: []string{
+			"MSFT - gets the price of Microsoft stock",
+			"MSFT,IBM,INTC - gets the prices of three stocks",
+			"SAP.DE in USD - gets the price of SAP in US dollars",
+			"BTC-USD,EURUSD - gets the price of Bitcoin and of the euro in US dollars",
+		},
+		Handler: func(request *commands.Request) {
+			getQuotes(request.SlashCommand, request.Writer)
+		},
+	})
+
+	mustRegister(registry, &commands.Command{
+		Name:        "/quote-alert",
+		Aliases:     []string{"/quoted-alert"},
+		Usage:       "[new] [symbol price [above|below] [#channel] [expires=YYYY-MM-DD] [currency=XXX]] [symbol delete] [deleteall]",
+		Description: "Sets up a subscription to a price alert for the specified symbol.",
+		Examples: []string{
+			"- lists all of the alerts you have",
+			"new - opens a dialog to create a new alert",
+			"MSFT 130 - sends an alert when Microsoft stock reaches $130",
+			"MSFT 130 #myalerts - sends an alert  to the #myalert Slack channel when Microsoft stock reaches $130",
+			"MSFT 130 BELOW - sends an alert when Microsoft stock goes below $130",
+			"MSFT 120 currency=EUR - sends an alert when Microsoft stock reaches 120 euros",
+			`BRK.B price=215 direction=below channel="#myalerts" expires=2019-12-31 - the same, using options`,
+			"MSFT delete - removes the existing alert on MSFT stock that you have subscribed to",
+			"deleteall - deletes all alerts that you have",
+		},
+		Subcommands: []commands.Subcommand{
+			{
+				Name:  "check",
+				Usage: "checks all of the alerts for price breaches right now",
+				Alone: true,
+				Handler: func(request *commands.Request) {
+					checkForPriceBreaches(request.Writer)
+				},
+			},
+		},
+		Handler: func(request *commands.Request) {
+			theAlertManager.HandleQuoteAlert(request.SlashCommand, request.Writer, request.Log)
+		},
+	})
+
+	mustRegister(registry, &commands.Command{
+		Name:        "/quote-search",
+		Usage:       "company name or part of a symbol",
+		Description: "Finds the symbols that match a company name, with their exchange and currency.",
+		Examples: []string{
+			"microsoft - finds Microsoft's symbols on every exchange",
+			"BRK - finds the symbols that start with BRK",
+		},
+		Handler: handleQuoteSearch,
+	})
+
+	mustRegister(registry, &commands.Command{
+		Name:        "/quote-info",
+		Usage:       "symbol",
+		Description: "Shows the market cap, P/E, EPS, dividend yield and 52-week range of a stock.",
+		Examples: []string{
+			"MSFT - shows the fundamentals of Microsoft",
+		},
+		Handler: handleQuoteInfo,
+	})
+
+	mustRegister(registry, &commands.Command{
+		Name:        "/quote-events",
+		Usage:       "[symbol[,symbol,...]]",
+		Description: "Shows the earnings, ex-dividend dates and splits of the next 30 days.",
+		Examples: []string{
+			"- shows the events of the stocks that you have alerts on",
+			"MSFT,AAPL - shows the events of Microsoft and Apple",
+		},
+		Handler: handleQuoteEvents,
+	})
+
+	mustRegister(registry, &commands.Command{
+		Name:        "/quote-news",
+		Usage:       "symbol",
+		Description: "Shows the latest headlines about a stock, and sends you alerts about its news.",
+		Examples: []string{
+			"MSFT - shows the news about Microsoft of the last 3 days",
+		},
+		Subcommands: []commands.Subcommand{
+			{
+				Name:    "alert",
+				Usage:   "symbol keyword=word|volume=N [#channel] - alerts you to new articles that mention the word, or to N or more articles in a day",
+				Handler: createNewsAlert,
+			},
+			{
+				Name:    "alerts",
+				Usage:   "lists your news alerts",
+				Handler: listNewsAlerts,
+			},
+			{
+				Name:    "delete",
+				Usage:   "id - deletes one of your news alerts",
+				Handler: deleteNewsAlert,
+			},
+		},
+		Handler: handleQuoteNews,
+	})
+
+	mustRegister(registry, &commands.Command{
+		Name:        "/quote-settings",
+		Usage:       "[decimals=N] [currency=XXX] [timezone=Area/City] [channel=#channel|none] [delivery=channel|dm] [events=on|off]",
+		Description: "Shows or changes the way that you see prices and receive alerts.",
+		Examples: []string{
+			"- shows your settings",
+			"decimals=4 - shows prices with 4 decimal places",
+			"timezone=Europe/London - shows times in London time",
+			"channel=#myalerts - sends new alerts that do not name a channel to #myalerts",
+			"delivery=dm - sends all of your alerts to you as a direct message",
+			"events=on - sends you a direct message the day before the earnings, dividends and splits of your alerted stocks",
+		},
+		Subcommands: []commands.Subcommand{
+			{
+				Name:    "reset",
+				Usage:   "goes back to the default settings",
+				Handler: resetQuoteSettings,
+			},
+		},
+		Handler: handleQuoteSettings,
+	})
+
+	return registry
+}
+
+// answerSlowly - answers a command whose answer needs a call to the quote provider. That can take longer than
+// the 3 seconds that Slack waits, so a slow answer is sent to the response_url after the placeholder text.
+func answerSlowly(request *commands.Request, placeholder string, answer func() slack.Message) {
+	results := make(chan slack.Message, 1)
+	go func() {
+		results <- answer()
+	}()
+
+	select {
+	case msg := <-results:
+		slackmessaging.WriteBlockResponse(request.Writer, msg)
+
+	case <-time.After(2500 * time.Millisecond):
+		slackmessaging.WriteResponse(request.Writer, placeholder)
+		responseURL := request.SlashCommand.ResponseURL
+		go func() {
+			if err := slackmessaging.RespondToInteraction(responseURL, <-results, false); err != nil {
+				request.Log.Warn("cannot send the answer", "command", request.SlashCommand.Command, "err", err)
+			}
+		}()
+	}
+}
+
+// textMessage - a plain text answer to a command
+func textMessage(text string) slack.Message {
+	return slack.Message{Msg: slack.Msg{Text: text}}
+}
+
+func mustRegister(registry *commands.Registry, command *commands.Command) {
+	if err := registry.Register(command); err != nil {
+		logger.Fatal("cannot register the slash command", "command", command.Name, "err", err)
+	}
+}
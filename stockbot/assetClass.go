@@ -0,0 +1,112 @@
+package stockbot
+
+import (
+	"regexp"
+	"strings"
+)
+
+// AssetClass - the kind of instrument that a symbol stands for. Each class can be quoted by a different provider.
+type AssetClass string
+
+// The asset classes that the bot can quote
+const (
+	AssetEquity AssetClass = "equity"
+	AssetETF    AssetClass = "etf"
+	AssetFX     AssetClass = "fx"
+	AssetCrypto AssetClass = "crypto"
+)
+
+// Instrument - a symbol in its normal form, along with what it is
+type Instrument struct {
+	Symbol     string // EURUSD for FX pairs, BTC-USD for crypto pairs, and the ticker for everything else
+	AssetClass AssetClass
+	Base       string // the currency or coin that a pair prices. Empty for equities and ETFs.
+	Currency   string // the currency that the price is in
+}
+
+// The currencies that can make up an FX pair, or be the quote currency of a crypto pair
+var fiatCurrencies = map[string]bool{
+	"USD": true, "EUR": true, "GBP": true, "JPY": true, "CHF": true, "CAD": true, "AUD": true, "NZD": true,
+	"SEK": true, "NOK": true, "DKK": true, "HKD": true, "SGD": true, "CNY": true, "INR": true, "MXN": true,
+	"BRL": true, "ZAR": true, "KRW": true, "PLN": true, "TRY": true, "ILS": true,
+}
+
+// The coins that can make up a crypto pair. Stablecoins can also be the quote currency.
+var cryptoCurrencies = map[string]bool{
+	"BTC": true, "ETH": true, "LTC": true, "XRP": true, "BCH": true, "ADA": true, "DOGE": true, "SOL": true,
+	"DOT": true, "LINK": true, "XLM": true, "EOS": true, "USDT": true, "USDC": true,
+}
+
+// Some of the most traded ETFs. They are quoted like equities, but the reference data can say what they are.
+var knownETFs = map[string]bool{
+	"SPY": true, "IVV": true, "VOO": true, "VTI": true, "QQQ": true, "DIA": true, "IWM": true, "EFA": true,
+	"EEM": true, "GLD": true, "SLV": true, "TLT": true, "AGG": true, "XLF": true, "XLK": true, "XLE": true,
+}
+
+// EUR/USD, EUR-USD, EURUSD and Yahoo's EURUSD=X are all the same pair
+var pairPattern = regexp.MustCompile(`^[A-Z]{3,5}[/\-]?[A-Z]{3,4}(=X)?$`)
+
+// NormalizeSymbol - works out the asset class of a symbol and puts it in the form that the providers expect
+func NormalizeSymbol(symbol string) Instrument {
+	symbol = strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(symbol), "$"))
+
+	if pairPattern.MatchString(symbol) {
+		if instrument, ok := splitPair(symbol); ok {
+			return instrument
+		}
+	}
+
+	if knownETFs[symbol] {
+		return Instrument{Symbol: symbol, AssetClass: AssetETF, Currency: defaultListingCurrency}
+	}
+	return Instrument{Symbol: symbol, AssetClass: AssetEquity, Currency: suffixCurrency(symbol)}
+}
+
+// splitPair - finds the two currencies in a pair. Without a separator, every split is tried, so that ETHUSDT is ETH-USDT.
+func splitPair(symbol string) (Instrument, bool) {
+	yahooFX := strings.HasSuffix(symbol, "=X")
+	symbol = strings.TrimSuffix(symbol, "=X")
+
+	var splits [][2]string
+	if separator := strings.IndexAny(symbol, "/-"); separator >= 0 {
+		splits = append(splits, [2]string{symbol[:separator], symbol[separator+1:]})
+	} else {
+		for i := 3; i <= len(symbol)-3; i++ {
+			splits = append(splits, [2]string{symbol[:i], symbol[i:]})
+		}
+	}
+
+	for _, split := range splits {
+		base, quote := split[0], split[1]
+		if base == quote {
+			continue
+		}
+		if fiatCurrencies[base] && fiatCurrencies[quote] {
+			return Instrument{Symbol: base + quote, AssetClass: AssetFX, Base: base, Currency: quote}, true
+		}
+		if !yahooFX && cryptoCurrencies[base] && (fiatCurrencies[quote] || cryptoCurrencies[quote]) {
+			return Instrument{Symbol: base + "-" + quote, AssetClass: AssetCrypto, Base: base, Currency: quote}, true
+		}
+	}
+	return Instrument{}, false
+}
+
+// suffixCurrency - the currency of the exchange in a symbol's suffix, like .DE or .L
+func suffixCurrency(symbol string) string {
+	if dot := strings.LastIndex(symbol, "."); dot >= 0 {
+		if currency, ok := suffixCurrencies[strings.ToUpper(symbol[dot+1:])]; ok {
+			return currency
+		}
+	}
+	return defaultListingCurrency
+}
+
+// ParseAssetClass - turns the name of an asset class in the appSettings into an AssetClass
+func ParseAssetClass(name string) (AssetClass, bool) {
+	switch class := AssetClass(strings.ToLower(name)); class {
+	case AssetEquity, AssetETF, AssetFX, AssetCrypto:
+		return class, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,79 @@
+package stockbot
+
+import (
+	"reflect"
+	"testing"
+
+	q "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+func TestNormalizeSymbol(t *testing.T) {
+	tests := []struct {
+		symbol string
+		want   Instrument
+	}{
+		{"msft", Instrument{Symbol: "MSFT", AssetClass: AssetEquity, Currency: "USD"}},
+		{"$BRK.B", Instrument{Symbol: "BRK.B", AssetClass: AssetEquity, Currency: "USD"}},
+		{"SAP.DE", Instrument{Symbol: "SAP.DE", AssetClass: AssetEquity, Currency: "EUR"}},
+		{"spy", Instrument{Symbol: "SPY", AssetClass: AssetETF, Currency: "USD"}},
+		{"EURUSD", Instrument{Symbol: "EURUSD", AssetClass: AssetFX, Base: "EUR", Currency: "USD"}},
+		{"eur/usd", Instrument{Symbol: "EURUSD", AssetClass: AssetFX, Base: "EUR", Currency: "USD"}},
+		{"GBPJPY=X", Instrument{Symbol: "GBPJPY", AssetClass: AssetFX, Base: "GBP", Currency: "JPY"}},
+		{"BTC-USD", Instrument{Symbol: "BTC-USD", AssetClass: AssetCrypto, Base: "BTC", Currency: "USD"}},
+		{"btcusd", Instrument{Symbol: "BTC-USD", AssetClass: AssetCrypto, Base: "BTC", Currency: "USD"}},
+		{"ETHUSDT", Instrument{Symbol: "ETH-USDT", AssetClass: AssetCrypto, Base: "ETH", Currency: "USDT"}},
+		{"DOGE/EUR", Instrument{Symbol: "DOGE-EUR", AssetClass: AssetCrypto, Base: "DOGE", Currency: "EUR"}},
+		{"ETH", Instrument{Symbol: "ETH", AssetClass: AssetEquity, Currency: "USD"}},
+		{"USDUSD", Instrument{Symbol: "USDUSD", AssetClass: AssetEquity, Currency: "USD"}},
+		{"GOOGL", Instrument{Symbol: "GOOGL", AssetClass: AssetEquity, Currency: "USD"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			if got := NormalizeSymbol(tt.symbol); got != tt.want {
+				t.Errorf("NormalizeSymbol() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeQuoteProvider - returns a fixed price for every symbol, and remembers what it was asked for
+type fakeQuoteProvider struct {
+	price   float32
+	symbols []string
+}
+
+func (provider *fakeQuoteProvider) FetchQuote(symbol string) float32 {
+	provider.symbols = append(provider.symbols, symbol)
+	return provider.price
+}
+
+func (provider *fakeQuoteProvider) SetAPIKey(apiKey string) {}
+
+func (provider *fakeQuoteProvider) Close() {}
+
+func TestStockbot_Quote_RoutesByAssetClass(t *testing.T) {
+	equities := &fakeQuoteProvider{price: 133.5}
+	etfs := &fakeQuoteProvider{price: 295.1}
+	bot := &Stockbot{providers: &providerSet{
+		quoteProvider:  equities,
+		quoteProviders: map[AssetClass]q.QuoteProvider{AssetETF: etfs},
+		pairProviders: map[AssetClass]q.FXProvider{
+			AssetFX:     &fakeFXProvider{rates: map[string]float32{"EURUSD": 1.125}},
+			AssetCrypto: &fakeFXProvider{rates: map[string]float32{"BTCUSD": 10500}},
+		},
+	}}
+
+	got := bot.Quote([]string{"msft", "SPY", "EUR/USD", "btc-usd"})
+	want := []QuoteInfo{
+		{Symbol: "MSFT", LastPrice: 133.5, Currency: "USD", AssetClass: AssetEquity},
+		{Symbol: "SPY", LastPrice: 295.1, Currency: "USD", AssetClass: AssetETF},
+		{Symbol: "EURUSD", LastPrice: 1.125, Currency: "USD", AssetClass: AssetFX},
+		{Symbol: "BTC-USD", LastPrice: 10500, Currency: "USD", AssetClass: AssetCrypto},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Stockbot.Quote() = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(equities.symbols, []string{"MSFT"}) || !reflect.DeepEqual(etfs.symbols, []string{"SPY"}) {
+		t.Errorf("the equity provider was asked for %v and the ETF provider for %v", equities.symbols, etfs.symbols)
+	}
+}
@@ -0,0 +1,66 @@
+package stockbot
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
+	q "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+// ErrEventsNotSupported - none of the quote providers know about earnings, dividends and splits
+var ErrEventsNotSupported = errors.New("corporate events need the alphavantage driver or an alphavantage API key")
+
+// ErrSplitsNotSupported - none of the quote providers know when stocks split
+var ErrSplitsNotSupported = errors.New("splits need the quandl or alphavantage driver, or an alphavantage API key")
+
+// createEventsProvider - the first candidate that has corporate events
+func (set *providerSet) createEventsProvider(appSettings *config.AppSettings) q.EventsProvider {
+	for _, provider := range set.capabilityCandidates(appSettings) {
+		if events, ok := provider.(q.EventsProvider); ok {
+			return events
+		}
+	}
+	return nil
+}
+
+// createSplitProvider - the first candidate that knows about splits
+func (set *providerSet) createSplitProvider(appSettings *config.AppSettings) q.SplitProvider {
+	for _, provider := range set.capabilityCandidates(appSettings) {
+		if splits, ok := provider.(q.SplitProvider); ok {
+			return splits
+		}
+	}
+	return nil
+}
+
+// FetchEvents - gets the earnings dates, ex-dividend dates and splits of a stock
+func (bot *Stockbot) FetchEvents(symbol string) ([]q.CorporateEvent, error) {
+	instrument := NormalizeSymbol(symbol)
+	if instrument.AssetClass == AssetFX || instrument.AssetClass == AssetCrypto {
+		return nil, fmt.Errorf("%s is a currency pair, and only stocks and ETFs have corporate events", instrument.Symbol)
+	}
+	providers, release := bot.acquire()
+	defer release()
+	eventsProvider := providers.eventsProvider
+	if eventsProvider == nil {
+		return nil, ErrEventsNotSupported
+	}
+	return eventsProvider.FetchEvents(instrument.Symbol)
+}
+
+// FetchSplits - gets the splits of a stock that took effect on or after the date
+func (bot *Stockbot) FetchSplits(symbol string, since time.Time) ([]q.Split, error) {
+	instrument := NormalizeSymbol(symbol)
+	if instrument.AssetClass == AssetFX || instrument.AssetClass == AssetCrypto {
+		return nil, nil
+	}
+	providers, release := bot.acquire()
+	defer release()
+	splitProvider := providers.splitProvider
+	if splitProvider == nil {
+		return nil, ErrSplitsNotSupported
+	}
+	return splitProvider.FetchSplits(instrument.Symbol, since)
+}
@@ -0,0 +1,100 @@
+package stockbot
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/magmasystems/SlackStockSlashCommand/metrics"
+	q "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+// How long an exchange rate is used before it is fetched again
+const defaultRateLifetime = 10 * time.Minute
+
+// The currency of the symbols that have no exchange suffix
+const defaultListingCurrency = "USD"
+
+// The currencies of the exchanges that a symbol suffix like .DE or .L stands for
+var suffixCurrencies = map[string]string{
+	"DE": "EUR", "F": "EUR", "PA": "EUR", "AS": "EUR", "MI": "EUR", "MC": "EUR", "BR": "EUR",
+	"L": "GBP", "SW": "CHF", "TO": "CAD", "V": "CAD", "AX": "AUD", "T": "JPY", "HK": "HKD",
+}
+
+// CurrencyConverter - converts prices with the rates from an FX provider.
+// Every quote may need a rate, so the rates are cached for a while.
+type CurrencyConverter struct {
+	provider     q.FXProvider
+	rateLifetime time.Duration
+	mutex        sync.Mutex
+	rates        map[string]cachedRate
+	now          func() time.Time
+}
+
+type cachedRate struct {
+	rate      float64
+	fetchedAt time.Time
+}
+
+// CreateCurrencyConverter - creates a converter on top of an FX provider
+func CreateCurrencyConverter(provider q.FXProvider, rateLifetime time.Duration) *CurrencyConverter {
+	if rateLifetime <= 0 {
+		rateLifetime = defaultRateLifetime
+	}
+	return &CurrencyConverter{provider: provider, rateLifetime: rateLifetime, rates: make(map[string]cachedRate), now: time.Now}
+}
+
+// Close - disposes of the FX provider
+func (converter *CurrencyConverter) Close() {
+	if converter.provider != nil {
+		converter.provider.Close()
+	}
+}
+
+// Rate - the number of toCurrency that one fromCurrency buys
+func (converter *CurrencyConverter) Rate(fromCurrency string, toCurrency string) (float64, error) {
+	fromCurrency, toCurrency = strings.ToUpper(fromCurrency), strings.ToUpper(toCurrency)
+	if fromCurrency == toCurrency {
+		return 1, nil
+	}
+
+	key := fromCurrency + toCurrency
+	converter.mutex.Lock()
+	cached, ok := converter.rates[key]
+	converter.mutex.Unlock()
+	fresh := ok && converter.now().Sub(cached.fetchedAt) < converter.rateLifetime
+	metrics.CacheLookup("rates", fresh)
+	if fresh {
+		return cached.rate, nil
+	}
+
+	if converter.provider == nil {
+		return 0, fmt.Errorf("there is no exchange rate provider to convert %s to %s", fromCurrency, toCurrency)
+	}
+	rate := float64(converter.provider.FetchRate(fromCurrency, toCurrency))
+	if rate <= 0 {
+		return 0, fmt.Errorf("there is no exchange rate from %s to %s", fromCurrency, toCurrency)
+	}
+
+	converter.mutex.Lock()
+	converter.rates[key] = cachedRate{rate: rate, fetchedAt: converter.now()}
+	converter.mutex.Unlock()
+
+	return rate, nil
+}
+
+// Convert - converts an amount from one currency to another
+func (converter *CurrencyConverter) Convert(amount float64, fromCurrency string, toCurrency string) (float64, error) {
+	rate, err := converter.Rate(fromCurrency, toCurrency)
+	if err != nil {
+		return 0, err
+	}
+	return amount * rate, nil
+}
+
+// ListingCurrency - the currency that a symbol is quoted in. For a pair, that is its second currency.
+// For everything else, it goes by the suffix of the exchange.
+func ListingCurrency(symbol string) string {
+	return NormalizeSymbol(symbol).Currency
+}
@@ -0,0 +1,90 @@
+package stockbot
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeFXProvider - returns fixed rates and counts the calls
+type fakeFXProvider struct {
+	rates map[string]float32
+	calls int
+}
+
+func (provider *fakeFXProvider) FetchRate(fromCurrency string, toCurrency string) float32 {
+	provider.calls++
+	return provider.rates[fromCurrency+toCurrency]
+}
+
+func (provider *fakeFXProvider) SetAPIKey(apiKey string) {}
+
+func (provider *fakeFXProvider) Close() {}
+
+func TestCurrencyConverter_Convert(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  float64
+		from    string
+		to      string
+		want    float64
+		wantErr bool
+	}{
+		{"same currency", 100, "USD", "usd", 100, false},
+		{"known rate", 100, "EUR", "USD", 112.5, false},
+		{"unknown rate", 100, "USD", "XYZ", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter := CreateCurrencyConverter(&fakeFXProvider{rates: map[string]float32{"EURUSD": 1.125}}, 0)
+
+			got, err := converter.Convert(tt.amount, tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CurrencyConverter.Convert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CurrencyConverter.Convert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurrencyConverter_CachesRates(t *testing.T) {
+	provider := &fakeFXProvider{rates: map[string]float32{"USDEUR": 0.875}}
+	converter := CreateCurrencyConverter(provider, time.Minute)
+
+	now := time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC)
+	converter.now = func() time.Time { return now }
+
+	converter.Rate("USD", "EUR")
+	now = now.Add(30 * time.Second)
+	converter.Rate("USD", "EUR")
+	if provider.calls != 1 {
+		t.Errorf("the rate was fetched %d times within its lifetime, want 1", provider.calls)
+	}
+
+	now = now.Add(time.Minute)
+	converter.Rate("USD", "EUR")
+	if provider.calls != 2 {
+		t.Errorf("the rate was fetched %d times after it expired, want 2", provider.calls)
+	}
+}
+
+func TestListingCurrency(t *testing.T) {
+	tests := []struct {
+		symbol string
+		want   string
+	}{
+		{"MSFT", "USD"},
+		{"BRK.B", "USD"},
+		{"SAP.DE", "EUR"},
+		{"vod.l", "GBP"},
+		{"7203.T", "JPY"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			if got := ListingCurrency(tt.symbol); got != tt.want {
+				t.Errorf("ListingCurrency() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package stockbot
+
+import (
+	"errors"
+	"fmt"
+
+	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
+	q "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+// ErrFundamentalsNotSupported - none of the quote providers have the key statistics of companies
+var ErrFundamentalsNotSupported = errors.New("fundamentals need the alphavantage driver or an alphavantage API key")
+
+// createFundamentalsProvider - the first candidate that has fundamentals
+func (set *providerSet) createFundamentalsProvider(appSettings *config.AppSettings) q.FundamentalsProvider {
+	for _, provider := range set.capabilityCandidates(appSettings) {
+		if fundamentals, ok := provider.(q.FundamentalsProvider); ok {
+			return fundamentals
+		}
+	}
+	return nil
+}
+
+// Fundamentals - gets the market cap, P/E, EPS, dividend yield and 52-week range of a stock
+func (bot *Stockbot) Fundamentals(symbol string) (*q.Fundamentals, error) {
+	instrument := NormalizeSymbol(symbol)
+	if instrument.AssetClass == AssetFX || instrument.AssetClass == AssetCrypto {
+		return nil, fmt.Errorf("%s is a currency pair, and only stocks and ETFs have fundamentals", instrument.Symbol)
+	}
+	providers, release := bot.acquire()
+	defer release()
+	fundamentalsProvider := providers.fundamentalsProvider
+	if fundamentalsProvider == nil {
+		return nil, ErrFundamentalsNotSupported
+	}
+
+	fundamentals, err := fundamentalsProvider.FetchFundamentals(instrument.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if fundamentals.Currency == "" {
+		fundamentals.Currency = instrument.Currency
+	}
+	if fundamentals.Name == "" {
+		if info := bot.SymbolInfo(instrument.Symbol); info != nil {
+			fundamentals.Name = info.Name
+		}
+	}
+	return fundamentals, nil
+}
@@ -0,0 +1,38 @@
+package stockbot
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
+	q "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+// ErrNewsNotSupported - none of the quote providers have news
+var ErrNewsNotSupported = errors.New("news needs the alphavantage driver or an alphavantage API key")
+
+// createNewsProvider - the first candidate that has news
+func (set *providerSet) createNewsProvider(appSettings *config.AppSettings) q.NewsProvider {
+	for _, provider := range set.capabilityCandidates(appSettings) {
+		if news, ok := provider.(q.NewsProvider); ok {
+			return news
+		}
+	}
+	return nil
+}
+
+// FetchNews - gets the articles about a stock that were published on or after the time, newest first
+func (bot *Stockbot) FetchNews(symbol string, since time.Time) ([]q.NewsItem, error) {
+	instrument := NormalizeSymbol(symbol)
+	if instrument.AssetClass == AssetFX || instrument.AssetClass == AssetCrypto {
+		return nil, fmt.Errorf("%s is a currency pair, and news is only available for stocks and ETFs", instrument.Symbol)
+	}
+	providers, release := bot.acquire()
+	defer release()
+	newsProvider := providers.newsProvider
+	if newsProvider == nil {
+		return nil, ErrNewsNotSupported
+	}
+	return newsProvider.FetchNews(instrument.Symbol, since)
+}
@@ -0,0 +1,153 @@
+package stockbot
+
+import (
+	"strings"
+	"sync"
+
+	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
+	q "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+// providerSet - the providers of the drivers in the appSettings. A set is never changed once it is created,
+// so that the Stockbot can replace it when the appSettings are reloaded without locking every quote.
+type providerSet struct {
+	inUse                sync.WaitGroup // the calls that are using the providers, which must return before they are closed
+	driver               string
+	quoteProvider        q.QuoteProvider
+	quoteProviders       map[AssetClass]q.QuoteProvider // overrides the quoteProvider for equities or ETFs
+	pairProviders        map[AssetClass]q.FXProvider    // prices the FX and crypto pairs
+	converter            *CurrencyConverter
+	symbolSearcher       q.SymbolSearcher       // finds symbols by name. Nil if no provider can.
+	fundamentalsProvider q.FundamentalsProvider // nil if no provider has fundamentals
+	eventsProvider       q.EventsProvider       // nil if no provider has corporate events
+	splitProvider        q.SplitProvider        // nil if no provider knows about splits
+	newsProvider         q.NewsProvider         // nil if no provider has news
+}
+
+// createProviderSet - the providers of the drivers and the API keys in the appSettings.
+// The quoteProvider replaces the one of the Driver, unless it is nil.
+func createProviderSet(appSettings *config.AppSettings, quoteProvider q.QuoteProvider) *providerSet {
+	driver := appSettings.Driver
+	apiKey := appSettings.APIKeys[driver]
+
+	set := new(providerSet)
+	set.driver = driver
+	var err error
+	if quoteProvider != nil {
+		set.quoteProvider = quoteProvider
+	} else if set.quoteProvider, err = quoteProviderFactory(driver, apiKey); err != nil {
+		logger.Error("cannot create the quote provider", "driver", driver, "err", err)
+	}
+
+	// AlphaVantage has exchange rates too. The other drivers get them from the ECB.
+	fxDriver := appSettings.FXDriver
+	if fxDriver == "" {
+		fxDriver = "exchangeratesapi"
+		if strings.EqualFold(driver, "alphavantage") {
+			fxDriver = driver
+		}
+	}
+	fxProvider, err := fxProviderFactory(fxDriver, appSettings.APIKeys[strings.ToLower(fxDriver)])
+	if err != nil {
+		logger.Warn("there is no exchange rate provider", "driver", fxDriver, "err", err)
+	}
+	set.converter = CreateCurrencyConverter(fxProvider, 0)
+
+	set.routeAssetClasses(appSettings, fxProvider)
+	set.symbolSearcher = set.createSymbolSearcher(appSettings)
+	set.fundamentalsProvider = set.createFundamentalsProvider(appSettings)
+	set.eventsProvider = set.createEventsProvider(appSettings)
+	set.splitProvider = set.createSplitProvider(appSettings)
+	set.newsProvider = set.createNewsProvider(appSettings)
+
+	return set
+}
+
+// routeAssetClasses - picks the provider of each asset class. Equities and ETFs go to the Driver, FX pairs to the
+// FX provider, and crypto pairs to AlphaVantage or Coinbase, unless the AssetDrivers in the appSettings say otherwise.
+func (set *providerSet) routeAssetClasses(appSettings *config.AppSettings, fxProvider q.FXProvider) {
+	set.quoteProviders = make(map[AssetClass]q.QuoteProvider)
+	set.pairProviders = make(map[AssetClass]q.FXProvider)
+
+	for name, driver := range appSettings.AssetDrivers {
+		class, ok := ParseAssetClass(name)
+		if !ok {
+			logger.Warn("the asset driver is not for an asset class", "class", name)
+			continue
+		}
+
+		apiKey := appSettings.APIKeys[strings.ToLower(driver)]
+		var err error
+		switch class {
+		case AssetEquity, AssetETF:
+			set.quoteProviders[class], err = quoteProviderFactory(driver, apiKey)
+		case AssetFX, AssetCrypto:
+			set.pairProviders[class], err = fxProviderFactory(driver, apiKey)
+		}
+		if err != nil {
+			logger.Warn("cannot quote the asset class with the driver", "class", class, "driver", driver, "err", err)
+			delete(set.quoteProviders, class)
+			delete(set.pairProviders, class)
+		}
+	}
+
+	if _, ok := set.pairProviders[AssetFX]; !ok {
+		set.pairProviders[AssetFX] = fxProvider
+	}
+	if _, ok := set.pairProviders[AssetCrypto]; !ok {
+		cryptoDriver := "coinbase"
+		if strings.EqualFold(appSettings.Driver, "alphavantage") {
+			cryptoDriver = "alphavantage"
+		}
+		set.pairProviders[AssetCrypto], _ = fxProviderFactory(cryptoDriver, appSettings.APIKeys[cryptoDriver])
+	}
+}
+
+// Close - disposes of the providers
+func (set *providerSet) Close() {
+	// The same provider can serve several asset classes
+	closed := make(map[interface{}]bool)
+	closeOnce := func(provider interface{ Close() }) {
+		if !closed[provider] {
+			closed[provider] = true
+			provider.Close()
+		}
+	}
+
+	if set.quoteProvider != nil {
+		closeOnce(set.quoteProvider)
+	}
+	for _, provider := range set.quoteProviders {
+		if provider != nil {
+			closeOnce(provider)
+		}
+	}
+	for _, provider := range set.pairProviders {
+		if provider != nil {
+			closeOnce(provider)
+		}
+	}
+	if set.converter != nil {
+		set.converter.Close()
+	}
+}
+
+// fetchPrice - asks the provider of the instrument's asset class for its price
+func (set *providerSet) fetchPrice(instrument Instrument) float32 {
+	switch instrument.AssetClass {
+	case AssetFX, AssetCrypto:
+		// A pair is priced like an exchange rate: how much of the second currency one of the first one buys
+		if provider := set.pairProviders[instrument.AssetClass]; provider != nil {
+			return provider.FetchRate(instrument.Base, instrument.Currency)
+		}
+		return 0
+	default:
+		if provider := set.quoteProviders[instrument.AssetClass]; provider != nil {
+			return provider.FetchQuote(instrument.Symbol)
+		}
+		if set.quoteProvider == nil {
+			return 0
+		}
+		return set.quoteProvider.FetchQuote(instrument.Symbol)
+	}
+}
@@ -0,0 +1,83 @@
+package stockbot
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
+)
+
+func TestStockbot_Reconfigure(t *testing.T) {
+	bot := CreateStockbot(&config.AppSettings{Driver: "quandl", APIKeys: map[string]string{"quandl": "demo"}}, nil)
+	before, release := bot.acquire()
+	release()
+	if before.driver != "quandl" || before.symbolSearcher != nil {
+		t.Fatalf("CreateStockbot() driver = %s, searcher = %v, want quandl without a searcher", before.driver, before.symbolSearcher)
+	}
+
+	// AlphaVantage can search for symbols, so the swap brings the symbol search with it
+	bot.Reconfigure(&config.AppSettings{Driver: "alphavantage", APIKeys: map[string]string{"alphavantage": "demo"}})
+	after, release := bot.acquire()
+	release()
+	if after.driver != "alphavantage" || after.symbolSearcher == nil {
+		t.Errorf("Stockbot.Reconfigure() driver = %s, searcher = %v, want alphavantage with a searcher", after.driver, after.symbolSearcher)
+	}
+	if before.driver != "quandl" {
+		t.Errorf("Stockbot.Reconfigure() changed the old set of providers to %s", before.driver)
+	}
+}
+
+// blockingProvider - a quote provider whose quotes wait until the test lets them go
+type blockingProvider struct {
+	fetching chan struct{}
+	proceed  chan struct{}
+	closed   chan struct{}
+}
+
+func (provider *blockingProvider) FetchQuote(symbol string) float32 {
+	close(provider.fetching)
+	<-provider.proceed
+	select {
+	case <-provider.closed:
+		return 0
+	default:
+		return 100
+	}
+}
+
+func (provider *blockingProvider) SetAPIKey(apiKey string) {}
+func (provider *blockingProvider) Close()                  { close(provider.closed) }
+
+func TestStockbot_ReconfigureDuringQuote(t *testing.T) {
+	provider := &blockingProvider{fetching: make(chan struct{}), proceed: make(chan struct{}), closed: make(chan struct{})}
+	bot := CreateStockbot(&config.AppSettings{Driver: "blocking"}, provider)
+
+	var wg sync.WaitGroup
+	var quote []QuoteInfo
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		quote = bot.QuoteSingle("MSFT")
+	}()
+	<-provider.fetching
+
+	bot.Reconfigure(&config.AppSettings{Driver: "quandl", APIKeys: map[string]string{"quandl": "demo"}})
+	select {
+	case <-provider.closed:
+		t.Fatal("Stockbot.Reconfigure() closed the old provider while it was quoting")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(provider.proceed)
+	wg.Wait()
+	if len(quote) != 1 || quote[0].LastPrice != 100 {
+		t.Errorf("Stockbot.QuoteSingle() = %+v, want the price from the old provider", quote)
+	}
+
+	select {
+	case <-provider.closed:
+	case <-time.After(time.Second):
+		t.Error("Stockbot.Reconfigure() did not close the old provider after the quote returned")
+	}
+}
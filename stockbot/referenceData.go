@@ -0,0 +1,115 @@
+package stockbot
+
+import (
+	"strings"
+	"sync"
+
+	q "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+	"github.com/magmasystems/SlackStockSlashCommand/referencedata"
+)
+
+// symbolLearner - makes sure that each unknown symbol is only looked up once at a time
+type symbolLearner struct {
+	mutex    sync.Mutex
+	inFlight map[string]bool
+}
+
+// SetSymbolStore - gives the bot somewhere to keep the names, exchanges and currencies of the symbols that it quotes.
+// Providers whose quotes carry the company name fill it in as they go.
+func (bot *Stockbot) SetSymbolStore(store referencedata.SymbolStoreOps) {
+	bot.symbols = store
+
+	providers, release := bot.acquire()
+	defer release()
+	providers.reportMetadataTo(bot.rememberMetadata)
+}
+
+// reportMetadataTo - passes what the providers learn about the symbols that they quote to the handler
+func (set *providerSet) reportMetadataTo(handler func(q.SymbolMetadata)) {
+	for _, provider := range append([]q.QuoteProvider{set.quoteProvider}, set.providersOfQuotes()...) {
+		if reporter, ok := provider.(q.MetadataReporter); ok {
+			reporter.SetMetadataHandler(handler)
+		}
+	}
+}
+
+func (set *providerSet) providersOfQuotes() []q.QuoteProvider {
+	providers := make([]q.QuoteProvider, 0, len(set.quoteProviders))
+	for _, provider := range set.quoteProviders {
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
+// SymbolInfo - what is known about a symbol, without asking a provider. Nil if the symbol has never been quoted.
+func (bot *Stockbot) SymbolInfo(symbol string) *referencedata.SymbolInfo {
+	if bot.symbols == nil {
+		return nil
+	}
+	return bot.symbols.Get(NormalizeSymbol(symbol).Symbol)
+}
+
+// rememberMetadata - saves what a provider told us about a symbol while it was quoting it
+func (bot *Stockbot) rememberMetadata(metadata q.SymbolMetadata) {
+	if bot.symbols == nil || metadata.Symbol == "" {
+		return
+	}
+
+	instrument := NormalizeSymbol(metadata.Symbol)
+	info := referencedata.SymbolInfo{
+		Symbol:    instrument.Symbol,
+		Name:      metadata.Name,
+		Exchange:  metadata.Exchange,
+		Currency:  strings.ToUpper(metadata.Currency),
+		AssetType: metadata.AssetType,
+	}
+	if info.Currency == "" {
+		info.Currency = instrument.Currency
+	}
+	if info.AssetType == "" {
+		info.AssetType = string(instrument.AssetClass)
+	}
+	bot.symbols.Save(info)
+}
+
+// learnSymbol - called after a symbol was quoted for the first time. The symbol search has the company name
+// and exchange. If the symbol cannot be found there, what the symbol itself says is saved, so that it is
+// not looked up again and can be validated without a quote.
+func (bot *Stockbot) learnSymbol(instrument Instrument) {
+	if bot.symbols == nil {
+		return
+	}
+
+	bot.learner.mutex.Lock()
+	if bot.learner.inFlight == nil {
+		bot.learner.inFlight = make(map[string]bool)
+	}
+	if bot.learner.inFlight[instrument.Symbol] {
+		bot.learner.mutex.Unlock()
+		return
+	}
+	bot.learner.inFlight[instrument.Symbol] = true
+	bot.learner.mutex.Unlock()
+
+	defer func() {
+		bot.learner.mutex.Lock()
+		delete(bot.learner.inFlight, instrument.Symbol)
+		bot.learner.mutex.Unlock()
+	}()
+
+	metadata := q.SymbolMetadata{Symbol: instrument.Symbol}
+
+	// The search only knows about listed securities, not about pairs
+	if instrument.AssetClass == AssetEquity || instrument.AssetClass == AssetETF {
+		if matches, err := bot.SearchSymbols(instrument.Symbol); err == nil {
+			for _, match := range matches {
+				if strings.EqualFold(match.Symbol, instrument.Symbol) {
+					metadata.Name, metadata.Exchange, metadata.Currency = match.Name, match.Region, match.Currency
+					break
+				}
+			}
+		}
+	}
+
+	bot.rememberMetadata(metadata)
+}
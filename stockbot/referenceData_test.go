@@ -0,0 +1,94 @@
+package stockbot
+
+import (
+	"testing"
+
+	q "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+	"github.com/magmasystems/SlackStockSlashCommand/referencedata"
+)
+
+// memorySymbolStore - a SymbolStore without a database
+type memorySymbolStore struct {
+	symbols map[string]referencedata.SymbolInfo
+}
+
+func (store *memorySymbolStore) Get(symbol string) *referencedata.SymbolInfo {
+	if info, ok := store.symbols[symbol]; ok {
+		return &info
+	}
+	return nil
+}
+
+func (store *memorySymbolStore) Save(info referencedata.SymbolInfo) error {
+	store.symbols[info.Symbol] = info
+	return nil
+}
+
+// reportingQuoteProvider - a provider whose quotes come with the company name
+type reportingQuoteProvider struct {
+	fakeQuoteProvider
+	onMetadata func(q.SymbolMetadata)
+}
+
+func (provider *reportingQuoteProvider) SetMetadataHandler(handler func(q.SymbolMetadata)) {
+	provider.onMetadata = handler
+}
+
+func (provider *reportingQuoteProvider) FetchQuote(symbol string) float32 {
+	provider.onMetadata(q.SymbolMetadata{Symbol: symbol, Name: "Microsoft Corporation", Exchange: "NASDAQ", Currency: "usd"})
+	return provider.fakeQuoteProvider.FetchQuote(symbol)
+}
+
+func TestStockbot_Quote_RemembersProviderMetadata(t *testing.T) {
+	store := &memorySymbolStore{symbols: make(map[string]referencedata.SymbolInfo)}
+	bot := &Stockbot{providers: &providerSet{quoteProvider: &reportingQuoteProvider{fakeQuoteProvider: fakeQuoteProvider{price: 133.5}}}}
+	bot.SetSymbolStore(store)
+
+	quote := bot.QuoteSingle("msft")[0]
+	if quote.Name != "Microsoft Corporation" || quote.Exchange != "NASDAQ" {
+		t.Errorf("Stockbot.QuoteSingle() = %+v, want the name and exchange of MSFT", quote)
+	}
+
+	info := bot.SymbolInfo("MSFT")
+	if info == nil || info.Currency != "USD" || info.AssetType != string(AssetEquity) {
+		t.Errorf("Stockbot.SymbolInfo() = %+v, want an equity listed in USD", info)
+	}
+}
+
+func TestStockbot_learnSymbol(t *testing.T) {
+	tests := []struct {
+		name       string
+		instrument Instrument
+		want       referencedata.SymbolInfo
+	}{
+		{
+			"found by the search",
+			NormalizeSymbol("IBM"),
+			referencedata.SymbolInfo{Symbol: "IBM", Name: "International Business Machines", Exchange: "United States", Currency: "USD", AssetType: "equity"},
+		},
+		{
+			"not found by the search",
+			NormalizeSymbol("SAP.DE"),
+			referencedata.SymbolInfo{Symbol: "SAP.DE", Currency: "EUR", AssetType: "equity"},
+		},
+		{
+			"a pair",
+			NormalizeSymbol("BTCUSD"),
+			referencedata.SymbolInfo{Symbol: "BTC-USD", Currency: "USD", AssetType: "crypto"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memorySymbolStore{symbols: make(map[string]referencedata.SymbolInfo)}
+			bot := &Stockbot{providers: &providerSet{symbolSearcher: &fakeSymbolSearcher{matches: []q.SymbolMatch{
+				{Symbol: "IBM", Name: "International Business Machines", Region: "United States", Currency: "USD"},
+			}}}}
+			bot.SetSymbolStore(store)
+
+			bot.learnSymbol(tt.instrument)
+			if got := store.symbols[tt.want.Symbol]; got != tt.want {
+				t.Errorf("Stockbot.learnSymbol() saved %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
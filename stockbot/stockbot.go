@@ -2,21 +2,33 @@ package stockbot
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
+	"github.com/magmasystems/SlackStockSlashCommand/framework/logging"
 
 	q "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
 	av "github.com/magmasystems/SlackStockSlashCommand/quoteproviders/alphavantageprovider"
+	"github.com/magmasystems/SlackStockSlashCommand/quoteproviders/coinbase"
+	ecb "github.com/magmasystems/SlackStockSlashCommand/quoteproviders/exchangeratesapi"
 	quandl "github.com/magmasystems/SlackStockSlashCommand/quoteproviders/quandlprovider"
 	wtd "github.com/magmasystems/SlackStockSlashCommand/quoteproviders/worldtradingdata"
+	"github.com/magmasystems/SlackStockSlashCommand/referencedata"
 )
 
+var logger = logging.For("stockbot")
+
 // QuoteInfo - contains info about a quote
 type QuoteInfo struct {
-	Symbol    string
-	LastPrice float32
+	Symbol     string
+	Name       string // the company name, if it is known
+	Exchange   string
+	LastPrice  float32
+	Currency   string // the currency that the symbol is listed in
+	AssetClass AssetClass
 }
 
 // BotOps - interface defining all operations the Stockbot can do
@@ -26,35 +38,117 @@ type BotOps interface {
 	Quote(symbols []string) []QuoteInfo
 	QuoteSingleAsync(symbol string)
 	QuoteAsync(symbols []string)
-	Config() config.AppSettings
+	ConvertPrice(symbol string, price float64, currency string) (float64, error)
+	SearchSymbols(keywords string) ([]q.SymbolMatch, error)
+	SuggestSymbols(symbol string, limit int) []q.SymbolMatch
+	SymbolInfo(symbol string) *referencedata.SymbolInfo
+	Fundamentals(symbol string) (*q.Fundamentals, error)
+	FetchEvents(symbol string) ([]q.CorporateEvent, error)
+	FetchSplits(symbol string, since time.Time) ([]q.Split, error)
+	FetchNews(symbol string, since time.Time) ([]q.NewsItem, error)
+	Ping() (string, error)
 }
 
+// The Stockbot is the only implementation of the BotOps outside of the tests
+var _ BotOps = (*Stockbot)(nil)
+
 // Stockbot - the bot that retrieves stock quotes fro a provider
 type Stockbot struct {
-	quoteProvider q.QuoteProvider
+	providersLock sync.RWMutex
+	providers     *providerSet // replaced as a whole by Reconfigure
+	symbols       referencedata.SymbolStoreOps
+	learner       symbolLearner
 	QuoteReceived chan []QuoteInfo
 }
 
-// CreateStockbot - creates a new instance of the StockBot
-func CreateStockbot() *Stockbot {
-	configMgr := new(config.ConfigManager)
-	appSettings := configMgr.Config()
-
-	driver := appSettings.Driver
-	apiKey := appSettings.APIKeys[driver]
-
+// CreateStockbot - creates a new instance of the StockBot, with the providers of the drivers in the appSettings.
+// A quoteProvider that is not nil is used instead of the one of the Driver, which lets the tests quote from a fake.
+func CreateStockbot(appSettings *config.AppSettings, quoteProvider q.QuoteProvider) *Stockbot {
 	bot := new(Stockbot)
-	bot.quoteProvider, _ = quoteProviderFactory(driver, apiKey)
+	bot.providers = createProviderSet(appSettings, quoteProvider)
 	bot.QuoteReceived = make(chan []QuoteInfo, 20)
 
 	return bot
 }
 
+// Reconfigure - replaces the providers with the ones of the drivers and API keys in the appSettings.
+// The quotes that are being fetched finish with the old providers, and the ones that start afterwards use the new ones.
+func (bot *Stockbot) Reconfigure(appSettings *config.AppSettings) {
+	providers := createProviderSet(appSettings, nil)
+	if bot.symbols != nil {
+		providers.reportMetadataTo(bot.rememberMetadata)
+	}
+
+	bot.providersLock.Lock()
+	old := bot.providers
+	bot.providers = providers
+	bot.providersLock.Unlock()
+	logger.Info("swapped the quote providers", "driver", providers.driver)
+
+	// Nothing can pick up the old set any more, so it is closed as soon as the calls that are using it return
+	if old != nil {
+		go func() {
+			old.inUse.Wait()
+			old.Close()
+			logger.Debug("closed the old quote providers", "driver", old.driver)
+		}()
+	}
+}
+
+// acquire - the providers to use for one call, which must call release when it is done with them.
+// The set is never changed, only replaced, so it can be used without the lock. Reconfigure waits for
+// every call to release the old set before it closes the old providers.
+func (bot *Stockbot) acquire() (providers *providerSet, release func()) {
+	bot.providersLock.RLock()
+	defer bot.providersLock.RUnlock()
+	if bot.providers == nil {
+		return &providerSet{}, func() {}
+	}
+	bot.providers.inUse.Add(1)
+	return bot.providers, bot.providers.inUse.Done
+}
+
 // Close - disposes of the resources of a stock bot
 func (bot *Stockbot) Close() {
-	if bot.quoteProvider != nil {
-		bot.quoteProvider.Close()
+	bot.providersLock.RLock()
+	providers := bot.providers
+	bot.providersLock.RUnlock()
+	if providers != nil {
+		providers.Close()
+	}
+}
+
+// The symbol that Ping quotes
+const pingSymbol = "MSFT"
+
+// Ping - quotes a well-known stock with the default provider, to see if the provider can be reached.
+// Each ping uses some of the provider's quota, so the health checks cache the result.
+func (bot *Stockbot) Ping() (string, error) {
+	providers, release := bot.acquire()
+	defer release()
+	if providers.quoteProvider == nil {
+		return "", fmt.Errorf("there is no quote provider for the driver %s", providers.driver)
+	}
+	if price := providers.fetchPrice(NormalizeSymbol(pingSymbol)); price == 0 {
+		return "", fmt.Errorf("%s cannot quote %s", providers.driver, pingSymbol)
 	}
+	return fmt.Sprintf("%s quoted %s", providers.driver, pingSymbol), nil
+}
+
+// ConvertPrice - converts a price of the symbol from its listing currency into another currency.
+// An empty currency means the listing currency.
+func (bot *Stockbot) ConvertPrice(symbol string, price float64, currency string) (float64, error) {
+	listingCurrency := ListingCurrency(symbol)
+	if currency == "" || strings.EqualFold(currency, listingCurrency) {
+		return price, nil
+	}
+	providers, release := bot.acquire()
+	defer release()
+	converter := providers.converter
+	if converter == nil {
+		return 0, fmt.Errorf("there is no exchange rate provider to convert %s to %s", listingCurrency, currency)
+	}
+	return converter.Convert(price, listingCurrency, currency)
 }
 
 // QuoteAsync - gets the price for one or more stocks, and sends a message into the channel when the quotes are ready
@@ -82,9 +176,7 @@ func (bot *Stockbot) Quote(symbols []string) []QuoteInfo {
 		if symbol == "" {
 			continue
 		}
-		price := bot.quoteProvider.FetchQuote(symbol)
-		quoteInfo[idx].LastPrice = price
-		quoteInfo[idx].Symbol = symbol
+		quoteInfo[idx] = bot.quoteInstrument(NormalizeSymbol(symbol))
 	}
 
 	return quoteInfo[0:n]
@@ -104,8 +196,7 @@ func (bot *Stockbot) QuoteWG(symbols []string) []QuoteInfo {
 		wg.Add(1)
 
 		go func(b *Stockbot, sym string, qi *QuoteInfo, w *sync.WaitGroup) {
-			qi.Symbol = sym
-			qi.LastPrice = b.quoteProvider.FetchQuote(sym)
+			*qi = b.quoteInstrument(NormalizeSymbol(sym))
 			w.Done()
 		}(bot, symbol, &quoteInfo[idx], &wg)
 	}
@@ -115,6 +206,30 @@ func (bot *Stockbot) QuoteWG(symbols []string) []QuoteInfo {
 	return quoteInfo[0:n]
 }
 
+// quoteInstrument - gets the price of an instrument, along with its name and exchange if they are known.
+// The name of a symbol that is quoted for the first time is looked up in the background.
+func (bot *Stockbot) quoteInstrument(instrument Instrument) QuoteInfo {
+	providers, release := bot.acquire()
+	defer release()
+	quoteInfo := QuoteInfo{
+		Symbol:     instrument.Symbol,
+		Currency:   instrument.Currency,
+		AssetClass: instrument.AssetClass,
+		LastPrice:  providers.fetchPrice(instrument),
+	}
+	if bot.symbols == nil || quoteInfo.LastPrice == 0 {
+		return quoteInfo
+	}
+
+	if info := bot.symbols.Get(instrument.Symbol); info != nil {
+		quoteInfo.Name = info.Name
+		quoteInfo.Exchange = info.Exchange
+	} else {
+		go bot.learnSymbol(instrument)
+	}
+	return quoteInfo
+}
+
 // quoteProviderFactory - a factory that creates a quote provider
 func quoteProviderFactory(providerName string, apiKey string) (provider q.QuoteProvider, errs error) {
 	switch strings.ToLower(providerName) {
@@ -130,3 +245,19 @@ func quoteProviderFactory(providerName string, apiKey string) (provider q.QuoteP
 
 	return provider, nil
 }
+
+// fxProviderFactory - a factory that creates an exchange rate provider
+func fxProviderFactory(providerName string, apiKey string) (provider q.FXProvider, errs error) {
+	switch strings.ToLower(providerName) {
+	case "alphavantage":
+		provider = av.CreateFXProvider(apiKey)
+	case "exchangeratesapi":
+		provider = ecb.CreateFXProvider(apiKey)
+	case "coinbase":
+		provider = coinbase.CreateFXProvider(apiKey)
+	default:
+		return nil, errors.New("the FX Provider cannot be found")
+	}
+
+	return provider, nil
+}
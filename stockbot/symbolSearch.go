@@ -0,0 +1,127 @@
+package stockbot
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
+	q "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+	av "github.com/magmasystems/SlackStockSlashCommand/quoteproviders/alphavantageprovider"
+)
+
+// ErrSearchNotSupported - none of the quote providers can search for symbols
+var ErrSearchNotSupported = errors.New("symbol search needs the alphavantage driver or an alphavantage API key")
+
+// capabilityCandidates - the providers that are asked for the optional capabilities, like the symbol search.
+// The providers that quote equities come first. AlphaVantage comes last, as long as there is a key for it.
+func (set *providerSet) capabilityCandidates(appSettings *config.AppSettings) []q.QuoteProvider {
+	candidates := []q.QuoteProvider{set.quoteProviders[AssetEquity], set.quoteProvider, set.quoteProviders[AssetETF]}
+	if apiKey := appSettings.APIKeys["alphavantage"]; apiKey != "" {
+		candidates = append(candidates, av.CreateQuoteProvider(apiKey))
+	}
+	return candidates
+}
+
+// createSymbolSearcher - the first candidate that can search for symbols
+func (set *providerSet) createSymbolSearcher(appSettings *config.AppSettings) q.SymbolSearcher {
+	for _, provider := range set.capabilityCandidates(appSettings) {
+		if searcher, ok := provider.(q.SymbolSearcher); ok {
+			return searcher
+		}
+	}
+	return nil
+}
+
+// SearchSymbols - finds the symbols whose ticker or company name match the keywords, closest first
+func (bot *Stockbot) SearchSymbols(keywords string) ([]q.SymbolMatch, error) {
+	keywords = strings.TrimSpace(keywords)
+	if keywords == "" {
+		return nil, errors.New("there is nothing to search for")
+	}
+	providers, release := bot.acquire()
+	defer release()
+	symbolSearcher := providers.symbolSearcher
+	if symbolSearcher == nil {
+		return nil, ErrSearchNotSupported
+	}
+
+	matches, err := symbolSearcher.SearchSymbols(keywords)
+	if err != nil {
+		return nil, fmt.Errorf("the symbol search failed: %v", err)
+	}
+
+	rankMatches(keywords, matches)
+	return matches, nil
+}
+
+// SuggestSymbols - the few symbols that are closest to one that cannot be quoted. Errors just mean no suggestions.
+func (bot *Stockbot) SuggestSymbols(symbol string, limit int) []q.SymbolMatch {
+	matches, err := bot.SearchSymbols(symbol)
+	if err != nil {
+		return nil
+	}
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// rankMatches - sorts the matches by how few typing mistakes separate the keywords from the ticker
+// or from the start of the company name. Ties keep the order of the provider's own score.
+func rankMatches(keywords string, matches []q.SymbolMatch) {
+	keywords = strings.ToUpper(keywords)
+	distance := func(match q.SymbolMatch) int {
+		best := editDistance(keywords, strings.ToUpper(match.Symbol))
+		name := []rune(strings.ToUpper(match.Name))
+		if len(name) > len([]rune(keywords)) {
+			name = name[:len([]rune(keywords))]
+		}
+		if d := editDistance(keywords, string(name)); d < best {
+			best = d
+		}
+		return best
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		di, dj := distance(matches[i]), distance(matches[j])
+		if di != dj {
+			return di < dj
+		}
+		return matches[i].Score > matches[j].Score
+	})
+}
+
+// editDistance - the Levenshtein distance between two strings
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+func min3(a int, b int, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
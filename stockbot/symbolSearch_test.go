@@ -0,0 +1,79 @@
+package stockbot
+
+import (
+	"reflect"
+	"testing"
+
+	q "github.com/magmasystems/SlackStockSlashCommand/quoteproviders"
+)
+
+// fakeSymbolSearcher - returns the same matches for every search
+type fakeSymbolSearcher struct {
+	matches []q.SymbolMatch
+}
+
+func (searcher *fakeSymbolSearcher) SearchSymbols(keywords string) ([]q.SymbolMatch, error) {
+	return append([]q.SymbolMatch(nil), searcher.matches...), nil
+}
+
+func TestStockbot_SearchSymbols(t *testing.T) {
+	searcher := &fakeSymbolSearcher{matches: []q.SymbolMatch{
+		{Symbol: "MSF.DE", Name: "Microsoft Corporation", Score: 0.8},
+		{Symbol: "MSFT", Name: "Microsoft Corporation", Score: 0.6},
+		{Symbol: "MSI", Name: "Motorola Solutions Inc", Score: 0.9},
+		{Symbol: "SFT", Name: "Shift Technologies", Score: 0.5},
+	}}
+	bot := &Stockbot{providers: &providerSet{symbolSearcher: searcher}}
+
+	tests := []struct {
+		name     string
+		keywords string
+		want     []string
+	}{
+		{"a typo in the ticker", "MSFTT", []string{"MSFT", "SFT", "MSI", "MSF.DE"}},
+		{"the start of the company name", "microsoft", []string{"MSF.DE", "MSFT", "SFT", "MSI"}},
+		{"ties keep the provider's score", "MS", []string{"MSI", "MSF.DE", "MSFT", "SFT"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := bot.SearchSymbols(tt.keywords)
+			if err != nil {
+				t.Fatalf("Stockbot.SearchSymbols() error = %v", err)
+			}
+			var got []string
+			for _, match := range matches {
+				got = append(got, match.Symbol)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Stockbot.SearchSymbols() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStockbot_SearchSymbols_NotSupported(t *testing.T) {
+	bot := &Stockbot{}
+	if _, err := bot.SearchSymbols("MSFT"); err != ErrSearchNotSupported {
+		t.Errorf("Stockbot.SearchSymbols() error = %v, want %v", err, ErrSearchNotSupported)
+	}
+	if got := bot.SuggestSymbols("MSFT", 3); got != nil {
+		t.Errorf("Stockbot.SuggestSymbols() = %v, want nil", got)
+	}
+}
+
+func Test_editDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"MSFT", "MSFT", 0},
+		{"MSFTT", "MSFT", 1},
+		{"APPL", "AAPL", 1},
+		{"", "IBM", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
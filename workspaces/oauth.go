@@ -0,0 +1,173 @@
+package workspaces
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	config "github.com/magmasystems/SlackStockSlashCommand/configuration"
+	"github.com/nlopes/slack"
+)
+
+// The page that asks the user to approve the installation
+const authorizeURL = "https://slack.com/oauth/v2/authorize"
+
+// The cookie that carries the state from the install page to the redirect, so that nobody can forge the redirect
+const stateCookieName = "slack_oauth_state"
+
+// The bot scopes that the slash commands, notifications, modals and cashtags need
+var defaultScopes = []string{"commands", "chat:write", "im:write", "app_mentions:read", "channels:history"}
+
+// OAuthHandler - runs the OAuth v2 flow that installs the bot into a workspace
+type OAuthHandler struct {
+	settings config.OAuthSettings
+	apiURL   string
+	store    WorkspaceStoreOps
+}
+
+// oauthAccessResponse - what oauth.v2.access returns
+type oauthAccessResponse struct {
+	slack.SlackResponse
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	Scope       string `json:"scope"`
+	BotUserID   string `json:"bot_user_id"`
+	Team        struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"team"`
+	AuthedUser struct {
+		ID string `json:"id"`
+	} `json:"authed_user"`
+}
+
+// CreateOAuthHandler - creates the handler for the install and redirect endpoints.
+// The apiURL is only needed to talk to a fake Slack server.
+func CreateOAuthHandler(settings config.OAuthSettings, apiURL string, store WorkspaceStoreOps) *OAuthHandler {
+	if apiURL == "" {
+		apiURL = slack.APIURL
+	}
+	if len(settings.Scopes) == 0 {
+		settings.Scopes = defaultScopes
+	}
+	return &OAuthHandler{settings: settings, apiURL: apiURL, store: store}
+}
+
+// HandleInstall - sends the user to Slack to approve the installation
+func (handler *OAuthHandler) HandleInstall(w http.ResponseWriter, r *http.Request) {
+	state, err := newState()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookieName,
+		Value:    state,
+		Path:     "/",
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+	})
+
+	params := url.Values{}
+	params.Set("client_id", handler.settings.ClientID)
+	params.Set("scope", strings.Join(handler.settings.Scopes, ","))
+	params.Set("state", state)
+	if handler.settings.RedirectURL != "" {
+		params.Set("redirect_uri", handler.settings.RedirectURL)
+	}
+
+	http.Redirect(w, r, authorizeURL+"?"+params.Encode(), http.StatusFound)
+}
+
+// HandleRedirect - Slack sends the user back here with a code, which we exchange for the bot token of the workspace
+func (handler *OAuthHandler) HandleRedirect(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if reason := query.Get("error"); reason != "" {
+		logger.Info("the installation was not approved", "reason", reason)
+		http.Error(w, "The Stockbot was not installed: "+reason, http.StatusForbidden)
+		return
+	}
+
+	cookie, err := r.Cookie(stateCookieName)
+	if err != nil || cookie.Value == "" || cookie.Value != query.Get("state") {
+		logger.Warn("the state of the OAuth redirect does not match the one that we sent")
+		http.Error(w, "The installation link has expired. Please start again.", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: stateCookieName, Path: "/", MaxAge: -1})
+
+	code := query.Get("code")
+	if code == "" {
+		http.Error(w, "Slack did not send an authorization code", http.StatusBadRequest)
+		return
+	}
+
+	workspace, err := handler.exchangeCode(code)
+	if err != nil {
+		logger.Warn("cannot exchange the OAuth code", "err", err)
+		http.Error(w, "Slack did not accept the installation. Please try again.", http.StatusBadGateway)
+		return
+	}
+
+	if err = handler.store.Save(workspace); err != nil {
+		http.Error(w, "The installation could not be saved. Please try again.", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("installed into the workspace", "team", workspace.TeamID, "name", workspace.TeamName)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "The Stockbot has been installed into %s. Type /quote help in Slack to get started.\n", workspace.TeamName)
+}
+
+// exchangeCode - calls oauth.v2.access
+func (handler *OAuthHandler) exchangeCode(code string) (*Workspace, error) {
+	form := url.Values{}
+	form.Set("client_id", handler.settings.ClientID)
+	form.Set("client_secret", handler.settings.ClientSecret)
+	form.Set("code", code)
+	if handler.settings.RedirectURL != "" {
+		form.Set("redirect_uri", handler.settings.RedirectURL)
+	}
+
+	resp, err := http.PostForm(handler.apiURL+"oauth.v2.access", form)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	response := oauthAccessResponse{}
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	if err = response.Err(); err != nil {
+		return nil, err
+	}
+	if response.AccessToken == "" || response.Team.ID == "" {
+		return nil, errors.New("oauth.v2.access did not return a bot token")
+	}
+
+	return &Workspace{
+		TeamID:      response.Team.ID,
+		TeamName:    response.Team.Name,
+		BotUserID:   response.BotUserID,
+		BotToken:    response.AccessToken,
+		Scope:       response.Scope,
+		InstalledBy: response.AuthedUser.ID,
+	}, nil
+}
+
+func newState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
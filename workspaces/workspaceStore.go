@@ -0,0 +1,126 @@
+// Package workspaces - the Slack workspaces that the bot has been installed into through OAuth
+package workspaces
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/magmasystems/SlackStockSlashCommand/framework/logging"
+)
+
+var logger = logging.For("workspaces")
+
+// Workspace - a team that installed the bot, along with the bot token that Slack issued for it
+type Workspace struct {
+	TeamID      string
+	TeamName    string
+	BotUserID   string
+	BotToken    string
+	Scope       string
+	InstalledBy string
+	InstalledAt time.Time
+}
+
+// WorkspaceStoreOps - the operations that the WorkspaceStore can perform
+type WorkspaceStoreOps interface {
+	Save(workspace *Workspace) error
+	Get(teamID string) (*Workspace, error)
+	Delete(teamID string) error
+	BotToken(teamID string) (string, error)
+}
+
+// WorkspaceStore - keeps the workspaces in the database, with a cache in front of it,
+// since every notification needs to look up the bot token of its team
+type WorkspaceStore struct {
+	WorkspaceStoreOps
+	db    *sql.DB
+	mutex sync.RWMutex
+	cache map[string]*Workspace
+}
+
+// CreateWorkspaceStore - creates a store on top of an open database
+func CreateWorkspaceStore(db *sql.DB) *WorkspaceStore {
+	return &WorkspaceStore{db: db, cache: make(map[string]*Workspace)}
+}
+
+// Save - adds a workspace, or replaces its token when the app is reinstalled
+func (store *WorkspaceStore) Save(workspace *Workspace) error {
+	sqlStatement := `
+INSERT INTO slackstockbot.workspace (teamid, teamname, botuserid, bottoken, scope, installedby, installedat)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (teamid) DO UPDATE SET teamname = $2, botuserid = $3, bottoken = $4, scope = $5, installedby = $6, installedat = $7`
+
+	if workspace.InstalledAt.IsZero() {
+		workspace.InstalledAt = time.Now()
+	}
+
+	_, err := store.db.Exec(sqlStatement, workspace.TeamID, workspace.TeamName, workspace.BotUserID,
+		workspace.BotToken, workspace.Scope, workspace.InstalledBy, workspace.InstalledAt)
+	if err != nil {
+		logger.Error("cannot save the workspace", "team", workspace.TeamID, "err", err)
+		return err
+	}
+
+	store.mutex.Lock()
+	store.cache[workspace.TeamID] = workspace
+	store.mutex.Unlock()
+
+	logger.Info("saved the workspace", "team", workspace.TeamID, "name", workspace.TeamName)
+	return nil
+}
+
+// Get - finds a workspace by its team id. Returns nil if the bot is not installed in the team.
+func (store *WorkspaceStore) Get(teamID string) (*Workspace, error) {
+	store.mutex.RLock()
+	workspace, ok := store.cache[teamID]
+	store.mutex.RUnlock()
+	if ok {
+		return workspace, nil
+	}
+
+	sqlStatement := `SELECT teamid, teamname, botuserid, bottoken, scope, installedby, installedat
+	FROM slackstockbot.workspace
+	WHERE teamid = $1`
+
+	workspace = new(Workspace)
+	row := store.db.QueryRow(sqlStatement, teamID)
+	err := row.Scan(&workspace.TeamID, &workspace.TeamName, &workspace.BotUserID, &workspace.BotToken,
+		&workspace.Scope, &workspace.InstalledBy, &workspace.InstalledAt)
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		return nil, nil
+	default:
+		logger.Error("cannot get the workspace", "team", teamID, "err", err)
+		return nil, err
+	}
+
+	store.mutex.Lock()
+	store.cache[teamID] = workspace
+	store.mutex.Unlock()
+
+	return workspace, nil
+}
+
+// Delete - forgets a workspace after the app was uninstalled or its token was revoked
+func (store *WorkspaceStore) Delete(teamID string) error {
+	store.mutex.Lock()
+	delete(store.cache, teamID)
+	store.mutex.Unlock()
+
+	_, err := store.db.Exec(`DELETE FROM slackstockbot.workspace WHERE teamid = $1`, teamID)
+	if err != nil {
+		logger.Error("cannot delete the workspace", "team", teamID, "err", err)
+	}
+	return err
+}
+
+// BotToken - the bot token of a team, or "" if the bot was not installed through OAuth
+func (store *WorkspaceStore) BotToken(teamID string) (string, error) {
+	workspace, err := store.Get(teamID)
+	if err != nil || workspace == nil {
+		return "", err
+	}
+	return workspace.BotToken, nil
+}